@@ -0,0 +1,126 @@
+package galice
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// MaxAnalyticsEvents is a maximum number of analytics events in one response
+const MaxAnalyticsEvents = 10
+
+// Names of events tracked by TrackSessionEvents middleware
+const (
+	EventSessionStarted = "session_started" // first request of session
+	EventSessionEnded   = "session_ended"   // response ending session
+	EventHandlerError   = "handler_error"   // AliceHandler returned error
+	EventIntentMatched  = "intent_matched"  // Alice NLU recognized intents, names are in "intents" attribute
+	EventFallbackHit    = "fallback_hit"    // response of fallback handler
+)
+
+// AnalyticsEvent is an Alice API representation of event sent to skill AppMetrica dashboard
+type AnalyticsEvent struct {
+	Name  string                 `json:"name"`
+	Value map[string]interface{} `json:"value,omitempty"`
+}
+
+// Analytics is an Alice API representation of response analytics data
+type Analytics struct {
+	Events []AnalyticsEvent `json:"events"`
+}
+
+// TrackEvent adds analytics event into current output. Attribute values must be strings,
+// numbers, booleans or nested maps with string keys. Error is returned for invalid attributes
+// and if output already has MaxAnalyticsEvents events.
+func (o *OutputData) TrackEvent(name string, attrs map[string]interface{}) error {
+	if name == "" {
+		return fmt.Errorf("Analytics event name is empty")
+	}
+	if o.Analytics != nil && len(o.Analytics.Events) >= MaxAnalyticsEvents {
+		return fmt.Errorf("Unable to track %v event: response already has %v events", name, MaxAnalyticsEvents)
+	}
+	if err := validateEventValue(reflect.ValueOf(attrs), ""); err != nil {
+		return fmt.Errorf("Unable to track %v event: %v", name, err)
+	}
+
+	if o.Analytics == nil {
+		o.Analytics = &Analytics{}
+	}
+	o.Analytics.Events = append(o.Analytics.Events, AnalyticsEvent{name, attrs})
+	return nil
+}
+
+func validateEventValue(v reflect.Value, path string) error {
+	if v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return nil
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("unsupported key type of %v: %v", path, v.Type().Key())
+		}
+		keys := v.MapKeys()
+		sort.Slice(keys, func(a, b int) bool {
+			return keys[a].String() < keys[b].String()
+		})
+		for _, k := range keys {
+			p := k.String()
+			if path != "" {
+				p = path + "." + p
+			}
+			if err := validateEventValue(v.MapIndex(k), p); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Invalid:
+		if path == "" {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unsupported value type of %v: %v", path, v.Kind())
+}
+
+// Middleware is a signature of function which wraps AliceHandler to add some common behavior
+type Middleware func(AliceHandler) AliceHandler
+
+// TrackSessionEvents is a Middleware which adds EventSessionStarted, EventSessionEnded,
+// EventIntentMatched, EventFallbackHit and EventHandlerError analytics events into responses
+func TrackSessionEvents(next AliceHandler) AliceHandler {
+	return func(i InputData) (OutputData, error) {
+		o, err := next(i)
+		var events []AnalyticsEvent
+		if i.Session.New {
+			events = append(events, AnalyticsEvent{Name: EventSessionStarted})
+		}
+		if len(i.Request.NLU.Intents) > 0 {
+			intents := make(map[string]interface{}, len(i.Request.NLU.Intents))
+			for name := range i.Request.NLU.Intents {
+				intents[name] = true
+			}
+			events = append(events, AnalyticsEvent{EventIntentMatched, map[string]interface{}{"intents": intents}})
+		}
+		if i.IsFallback() {
+			events = append(events, AnalyticsEvent{Name: EventFallbackHit})
+		}
+		if o.Response.EndSession {
+			events = append(events, AnalyticsEvent{Name: EventSessionEnded})
+		}
+		if err != nil {
+			events = append(events, AnalyticsEvent{Name: EventHandlerError})
+		}
+		for _, e := range events {
+			if tErr := o.TrackEvent(e.Name, e.Value); tErr != nil && err == nil {
+				err = tErr
+			}
+		}
+		return o, err
+	}
+}
@@ -0,0 +1,94 @@
+package galice
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrackEvent(t *testing.T) {
+	var o OutputData
+	require.NoError(t, o.TrackEvent("order", map[string]interface{}{
+		"pizza":  "margarita",
+		"count":  2,
+		"price":  9.5,
+		"promo":  false,
+		"extras": map[string]interface{}{"cheese": true},
+	}))
+	require.NoError(t, o.TrackEvent("menu_opened", nil))
+
+	data, err := json.Marshal(o.Analytics)
+	require.NoError(t, err)
+	require.Equal(t, `{"events":[{"name":"order","value":{"count":2,"extras":{"cheese":true},"pizza":"margarita","price":9.5,"promo":false}},{"name":"menu_opened"}]}`, string(data))
+
+	require.EqualError(t, o.TrackEvent("", nil), "Analytics event name is empty")
+	require.EqualError(t, o.TrackEvent("bad", map[string]interface{}{"list": []int{1}}), "Unable to track bad event: unsupported value type of list: slice")
+	require.EqualError(t, o.TrackEvent("bad", map[string]interface{}{"nested": map[string]interface{}{"nil": nil}}), "Unable to track bad event: unsupported value type of nested.nil: invalid")
+	require.EqualError(t, o.TrackEvent("bad", map[string]interface{}{"map": map[int]int{1: 1}}), "Unable to track bad event: unsupported key type of map: int")
+
+	for n := len(o.Analytics.Events); n < MaxAnalyticsEvents; n++ {
+		require.NoError(t, o.TrackEvent("event", nil))
+	}
+	require.EqualError(t, o.TrackEvent("event", nil), "Unable to track event event: response already has 10 events")
+}
+
+func TestTrackSessionEvents(t *testing.T) {
+	cli := New(true, true)
+	errStr := ""
+	cli.SetLogger(func(err error) {
+		errStr = err.Error()
+	})
+	cli.Use(TrackSessionEvents)
+	h := cli.CreateHandler(func(i InputData) (OutputData, error) {
+		if i.Request.Command == "ошибка" {
+			return NewOutput(i, NewResponse("Ой", "", false)), errors.New("test")
+		}
+		return NewOutput(i, NewResponse("Пока", "", true)), nil
+	})
+
+	req, err := http.NewRequest("POST", "/skill", bytes.NewReader([]byte(`{"session": {"new": true}, "request": {"command": "пока"}}`)))
+	require.NoError(t, err)
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Contains(t, rr.Body.String(), `"analytics":{"events":[{"name":"session_started"},{"name":"session_ended"}]}`)
+
+	req, err = http.NewRequest("POST", "/skill", bytes.NewReader([]byte(`{"request": {"command": "ошибка"}}`)))
+	require.NoError(t, err)
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Contains(t, rr.Body.String(), `"analytics":{"events":[{"name":"handler_error"}]}`)
+	require.Equal(t, "test", errStr)
+
+	req, err = http.NewRequest("POST", "/skill", bytes.NewReader([]byte(`{"request": {"command": "закажи пиццу",
+		"nlu": {"intents": {"order": {"slots": {}}, "pizza": {"slots": {}}}}}}`)))
+	require.NoError(t, err)
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Contains(t, rr.Body.String(), `"analytics":{"events":[{"name":"intent_matched","value":{"intents":{"order":true,"pizza":true}}},{"name":"session_ended"}]}`)
+}
+
+func TestTrackFallbackEvents(t *testing.T) {
+	cli := New(true, true)
+	cli.SetLogger(func(err error) {})
+	cli.SetFallbackResponse(NewResponse("Что-то пошло не так", "", false))
+	cli.Use(TrackSessionEvents)
+	h := cli.CreateHandler(func(i InputData) (OutputData, error) {
+		panic("test")
+	})
+
+	req, err := http.NewRequest("POST", "/skill", bytes.NewReader([]byte(`{"session": {"new": true}, "request": {"command": "привет"}}`)))
+	require.NoError(t, err)
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Contains(t, rr.Body.String(), "Что-то пошло не так")
+	require.Contains(t, rr.Body.String(), `"analytics":{"events":[{"name":"session_started"},{"name":"fallback_hit"}]}`)
+}
@@ -0,0 +1,227 @@
+package galice
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Alice API response limits
+const (
+	MaxTextLength            = 1024 // maximum length of response text
+	MaxTTSLength             = 1024 // maximum length of response TTS
+	MaxButtonTitleLength     = 64   // maximum length of button title
+	MaxURLLength             = 1024 // maximum length of button URL
+	MaxCardTitleLength       = 128  // maximum length of card title
+	MaxCardDescriptionLength = 256  // maximum length of card description
+	MaxCardItems             = 5    // maximum number of ItemsList card items
+)
+
+// ResponseBuilder builds OutputData for some InputData step by step:
+//
+//	o, err := galice.NewBuilder(i).
+//		Text("Какую пиццу?").
+//		Suggest("Маргарита").
+//		Suggest("Пепперони").
+//		Link("Меню", "https://example.com/menu").
+//		Build()
+type ResponseBuilder struct {
+	o            OutputData
+	sounds       string      // speaker tags played before TTS
+	filters      []TTSFilter // applied to TTS in Build
+	sanitizer    *Sanitizer  // used if TTS is derived from text
+	useSanitizer bool        // use sanitizer instead of default one
+}
+
+// NewBuilder creates new ResponseBuilder. Version and session data are taken from i.
+func NewBuilder(i InputData) *ResponseBuilder {
+	return &ResponseBuilder{o: NewOutput(i, Response{})}
+}
+
+// Text sets response text, it is also used as TTS unless TTS is called
+func (b *ResponseBuilder) Text(text string) *ResponseBuilder {
+	b.o.Response.Text = text
+	return b
+}
+
+// TTS sets response text to speach markup
+func (b *ResponseBuilder) TTS(tts string) *ResponseBuilder {
+	b.o.Response.TTS = tts
+	return b
+}
+
+// Sound adds sound uploaded with DialogsClient, sounds are played before TTS in order of adding
+func (b *ResponseBuilder) Sound(skillID string, id SoundID) *ResponseBuilder {
+	b.sounds += id.Speaker(skillID)
+	return b
+}
+
+// Sanitizer sets sanitizer used if TTS is derived from text instead of default one
+// (see SetDefaultSanitizer). Passing nil disables sanitizing.
+func (b *ResponseBuilder) Sanitizer(s *Sanitizer) *ResponseBuilder {
+	b.sanitizer, b.useSanitizer = s, true
+	return b
+}
+
+// FilterTTS adds filters which are applied to TTS when response is built,
+// e.g. ru.SpellNumbers. Speaker tags added with Sound are not filtered.
+func (b *ResponseBuilder) FilterTTS(filters ...TTSFilter) *ResponseBuilder {
+	b.filters = append(b.filters, filters...)
+	return b
+}
+
+// Suggest adds button which is hidden after user presses it or says anything
+func (b *ResponseBuilder) Suggest(title string) *ResponseBuilder {
+	b.o.Response.AddButton(title, true, "", nil)
+	return b
+}
+
+// SuggestPayload adds suggest button with payload
+func (b *ResponseBuilder) SuggestPayload(title string, payload interface{}) *ResponseBuilder {
+	b.o.Response.AddButton(title, true, "", payload)
+	return b
+}
+
+// Button adds inline button which stays under response text
+func (b *ResponseBuilder) Button(title string, payload interface{}) *ResponseBuilder {
+	b.o.Response.AddButton(title, false, "", payload)
+	return b
+}
+
+// Link adds inline button opening URL
+func (b *ResponseBuilder) Link(title, URL string) *ResponseBuilder {
+	b.o.Response.AddButton(title, false, URL, nil)
+	return b
+}
+
+// Card sets response card
+func (b *ResponseBuilder) Card(c Card) *ResponseBuilder {
+	b.o.Response.Card = &c
+	return b
+}
+
+// BigImage sets response card with single image
+func (b *ResponseBuilder) BigImage(imageID ImageID, title, description string) *ResponseBuilder {
+	return b.Card(Card{
+		Type:        CardTypeBigImage,
+		ImageID:     imageID,
+		Title:       title,
+		Description: description,
+	})
+}
+
+// ItemsList sets response card with list of images
+func (b *ResponseBuilder) ItemsList(header string, items ...CardItem) *ResponseBuilder {
+	c := Card{Type: CardTypeItemsList, Items: items}
+	if header != "" {
+		c.Header = &CardHeader{header}
+	}
+	return b.Card(c)
+}
+
+// Directive adds response directive, e.g. "start_account_linking"
+func (b *ResponseBuilder) Directive(name string, value interface{}) *ResponseBuilder {
+	if b.o.Response.Directives == nil {
+		b.o.Response.Directives = map[string]interface{}{}
+	}
+	b.o.Response.Directives[name] = value
+	return b
+}
+
+// ShowItem sets description of content provided in response to Show.Pull request
+func (b *ResponseBuilder) ShowItem(meta ShowItemMeta) *ResponseBuilder {
+	b.o.Response.ShowItemMeta = &meta
+	return b
+}
+
+// SessionState sets data returned in State.Session of the next request
+func (b *ResponseBuilder) SessionState(v interface{}) *ResponseBuilder {
+	b.o.SessionState = v
+	return b
+}
+
+// UserState sets data returned in State.User of next requests of the same user
+func (b *ResponseBuilder) UserState(v interface{}) *ResponseBuilder {
+	b.o.UserStateUpdate = v
+	return b
+}
+
+// ApplicationState sets data returned in State.Application of next requests from the same application
+func (b *ResponseBuilder) ApplicationState(v interface{}) *ResponseBuilder {
+	b.o.ApplicationState = v
+	return b
+}
+
+// ShouldListen sets if microphone should be turned on after response
+func (b *ResponseBuilder) ShouldListen(listen bool) *ResponseBuilder {
+	b.o.Response.ShouldListen = &listen
+	return b
+}
+
+// EndSession sets if current response is the last one in current session
+func (b *ResponseBuilder) EndSession(end bool) *ResponseBuilder {
+	b.o.Response.EndSession = end
+	return b
+}
+
+// Build validates response against Alice API limits and returns OutputData
+func (b *ResponseBuilder) Build() (OutputData, error) {
+	o := b.o
+	if o.Response.TTS == "" {
+		switch {
+		case !b.useSanitizer:
+			o.Response.TTS = sanitize(o.Response.Text)
+		case b.sanitizer != nil:
+			o.Response.TTS = b.sanitizer.Sanitize(o.Response.Text)
+		default:
+			o.Response.TTS = o.Response.Text
+		}
+	}
+	o.Response.FilterTTS(b.filters...)
+	if b.sounds != "" {
+		o.Response.TTS = b.sounds + " " + o.Response.TTS
+	}
+
+	var errs []string
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Sprintf(format, args...))
+		}
+	}
+
+	r := o.Response
+	check(r.Text != "", "response text is empty")
+	check(utf8.RuneCountInString(r.Text) <= MaxTextLength, "response text is longer than %v characters", MaxTextLength)
+	check(utf8.RuneCountInString(r.TTS) <= MaxTTSLength, "response TTS is longer than %v characters", MaxTTSLength)
+	for n, btn := range r.Buttons {
+		check(btn.Title != "", "button %v title is empty", n)
+		check(utf8.RuneCountInString(btn.Title) <= MaxButtonTitleLength, "button %v title is longer than %v characters", n, MaxButtonTitleLength)
+		check(utf8.RuneCountInString(btn.URL) <= MaxURLLength, "button %v URL is longer than %v characters", n, MaxURLLength)
+	}
+	if c := r.Card; c != nil {
+		switch c.Type {
+		case CardTypeBigImage:
+			check(c.ImageID != "", "card image ID is empty")
+			check(utf8.RuneCountInString(c.Title) <= MaxCardTitleLength, "card title is longer than %v characters", MaxCardTitleLength)
+			check(utf8.RuneCountInString(c.Description) <= MaxCardDescriptionLength, "card description is longer than %v characters", MaxCardDescriptionLength)
+		case CardTypeItemsList:
+			check(len(c.Items) > 0 && len(c.Items) <= MaxCardItems, "card must have from 1 to %v items", MaxCardItems)
+			for n, item := range c.Items {
+				check(item.ImageID != "", "card item %v image ID is empty", n)
+				check(utf8.RuneCountInString(item.Title) <= MaxCardTitleLength, "card item %v title is longer than %v characters", n, MaxCardTitleLength)
+				check(utf8.RuneCountInString(item.Description) <= MaxCardDescriptionLength, "card item %v description is longer than %v characters", n, MaxCardDescriptionLength)
+			}
+		}
+	}
+
+	if m := r.ShowItemMeta; m != nil {
+		check(m.ContentID != "", "show item content ID is empty")
+		check(!m.PublicationDate.IsZero(), "show item publication date is empty")
+	}
+
+	if len(errs) > 0 {
+		return o, errors.New("Invalid response: " + strings.Join(errs, "; "))
+	}
+	return o, nil
+}
@@ -0,0 +1,89 @@
+package galice
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseBuilder(t *testing.T) {
+	i := InputData{Version: "1.0", Session: Session{MessageID: 2, SessionID: "1"}}
+	o, err := NewBuilder(i).
+		Text("Какую пиццу?").
+		Suggest("Маргарита").
+		SuggestPayload("Пепперони", 2).
+		Button("Корзина", "cart").
+		Link("Меню", "https://example.com/menu").
+		BigImage("1027858/46r960da47f60207e924", "Пицца", "Вкусная").
+		Directive("start_account_linking", struct{}{}).
+		SessionState(map[string]int{"step": 1}).
+		ShouldListen(true).
+		Build()
+	require.NoError(t, err)
+
+	data, err := json.Marshal(o)
+	require.NoError(t, err)
+	require.Equal(t, `{"version":"1.0","session":{"new":false,"message_id":2,"session_id":"1","skill_id":"","user_id":""},`+
+		`"response":{"text":"Какую пиццу?","tts":"Какую пиццу?",`+
+		`"card":{"type":"BigImage","image_id":"1027858/46r960da47f60207e924","title":"Пицца","description":"Вкусная"},`+
+		`"buttons":[{"title":"Маргарита","hide":true},{"title":"Пепперони","hide":true,"payload":2},`+
+		`{"title":"Корзина","hide":false,"payload":"cart"},{"title":"Меню","hide":false,"url":"https://example.com/menu"}],`+
+		`"end_session":false,"should_listen":true,"directives":{"start_account_linking":{}}},`+
+		`"session_state":{"step":1}}`, string(data))
+
+	o, err = NewBuilder(i).Text("Пока").TTS("пока-пока").EndSession(true).Build()
+	require.NoError(t, err)
+	require.Equal(t, NewOutput(i, NewResponse("Пока", "пока-пока", true)), o)
+}
+
+func TestResponseBuilderItemsList(t *testing.T) {
+	o, err := NewBuilder(InputData{}).
+		Text("Меню").
+		ItemsList("Пиццы", CardItem{ImageID: "1", Title: "Маргарита"}, CardItem{ImageID: "2", Title: "Пепперони"}).
+		Build()
+	require.NoError(t, err)
+
+	data, err := json.Marshal(o.Response.Card)
+	require.NoError(t, err)
+	require.Equal(t, `{"type":"ItemsList","header":{"text":"Пиццы"},"items":[{"image_id":"1","title":"Маргарита"},{"image_id":"2","title":"Пепперони"}]}`, string(data))
+}
+
+func TestResponseBuilderSound(t *testing.T) {
+	o, err := NewBuilder(InputData{}).Text("Привет").Sound("skill-1", "s1").Build()
+	require.NoError(t, err)
+	require.Equal(t, `<speaker audio="dialogs-upload/skill-1/s1.opus"> Привет`, o.Response.TTS)
+}
+
+func TestResponseBuilderFilterTTS(t *testing.T) {
+	o, err := NewBuilder(InputData{}).
+		Text("Цена 100").
+		Sound("skill-1", "s1").
+		FilterTTS(func(s string) string { return strings.Replace(s, "100", "сто", -1) }, strings.ToUpper).
+		Build()
+	require.NoError(t, err)
+	require.Equal(t, "Цена 100", o.Response.Text)
+	require.Equal(t, `<speaker audio="dialogs-upload/skill-1/s1.opus"> ЦЕНА СТО`, o.Response.TTS)
+
+	r := NewResponse("Цена 100", "", false)
+	r.FilterTTS(strings.ToUpper)
+	require.Equal(t, "ЦЕНА 100", r.TTS)
+}
+
+func TestResponseBuilderValidation(t *testing.T) {
+	_, err := NewBuilder(InputData{}).Build()
+	require.EqualError(t, err, "Invalid response: response text is empty")
+
+	_, err = NewBuilder(InputData{}).
+		Text(strings.Repeat("а", MaxTextLength+1)).
+		Suggest(strings.Repeat("б", MaxButtonTitleLength+1)).
+		ItemsList("").
+		Build()
+	require.EqualError(t, err, "Invalid response: response text is longer than 1024 characters; "+
+		"response TTS is longer than 1024 characters; button 0 title is longer than 64 characters; "+
+		"card must have from 1 to 5 items")
+
+	_, err = NewBuilder(InputData{}).Text("Пицца").BigImage("", "", "").Build()
+	require.EqualError(t, err, "Invalid response: card image ID is empty")
+}
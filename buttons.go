@@ -0,0 +1,141 @@
+package galice
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/temapavloff/galice/match"
+)
+
+// buttonsKey is a storage key for buttons of the last response
+const buttonsKey = "galice.buttons"
+
+// ButtonsThreshold is a minimum score of voice selection of buttons. It is stricter than
+// default threshold of match.Matcher, so a single common word ("пиццу") does not select
+// "Пицца Маргарита". Threshold of matcher is used if it is higher.
+const ButtonsThreshold = 0.75
+
+// negations are words which invert meaning of utterance, a button is not selected by
+// utterance with negation unless the negation is a part of button title
+var negations = []string{"не", "нет", "ни", "без"}
+
+// savedButton is a button of the last response kept in storage
+type savedButton struct {
+	Title   string          `json:"title"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// saveButtons saves buttons of response to storage for voice selection
+func (c *Client) saveButtons(i InputData, o OutputData) error {
+	if len(o.Response.Buttons) == 0 {
+		return c.storage.Set(i.Session.SessionID, buttonsKey, nil)
+	}
+
+	buttons := make([]savedButton, len(o.Response.Buttons))
+	for n, b := range o.Response.Buttons {
+		buttons[n].Title = b.Title
+		if b.Payload != nil {
+			p, err := json.Marshal(b.Payload)
+			if err != nil {
+				return fmt.Errorf("Unable to save buttons: %v", err)
+			}
+			buttons[n].Payload = p
+		}
+	}
+
+	data, err := json.Marshal(buttons)
+	if err != nil {
+		return fmt.Errorf("Unable to save buttons: %v", err)
+	}
+	return c.storage.Set(i.Session.SessionID, buttonsKey, data)
+}
+
+// pressVoiceButton converts SimpleUtterance request matching title of one of saved buttons
+// into request of that button
+func (c *Client) pressVoiceButton(i *InputData) error {
+	if i.Request.Type != RequestTypeSimpleUtterance || len(i.Request.Payload) > 0 {
+		return nil
+	}
+
+	data, err := c.storage.Get(i.Session.SessionID, buttonsKey)
+	if err != nil {
+		return fmt.Errorf("Unable to load buttons: %v", err)
+	}
+	if data == nil {
+		return nil
+	}
+
+	var buttons []savedButton
+	if err = json.Unmarshal(data, &buttons); err != nil {
+		return fmt.Errorf("Unable to load buttons: %v", err)
+	}
+
+	tokens := i.Request.NLU.Tokens
+	if len(tokens) == 0 {
+		tokens = match.Tokenize(i.Request.Command)
+	}
+	n := c.bestButton(tokens, buttons)
+	if n < 0 {
+		return nil
+	}
+
+	if buttons[n].Payload != nil {
+		i.Request.Type = RequestTypeButtonPressed
+		i.Request.Payload = buttons[n].Payload
+	} else {
+		i.Request.Command = strings.Join(match.Tokenize(buttons[n].Title), " ")
+	}
+	return nil
+}
+
+// bestButton returns index of button best matching tokens, or -1 if there is no button with
+// score not less than ButtonsThreshold or several buttons have the same best score
+func (c *Client) bestButton(tokens []string, buttons []savedButton) int {
+	threshold := c.buttonsMatcher.Threshold()
+	if threshold < ButtonsThreshold {
+		threshold = ButtonsThreshold
+	}
+
+	best, bestScore, tie := -1, 0.0, false
+	for n, b := range buttons {
+		title := match.Tokenize(b.Title)
+		if hasNegation(tokens, title) {
+			continue
+		}
+		score := c.buttonsMatcher.Score(tokens, b.Title)
+		switch {
+		case score < threshold || score < bestScore:
+		case score == bestScore:
+			tie = true
+		default:
+			best, bestScore, tie = n, score, false
+		}
+	}
+	if tie {
+		return -1
+	}
+	return best
+}
+
+// hasNegation checks if tokens contain negation which title does not contain
+func hasNegation(tokens, title []string) bool {
+	for _, t := range tokens {
+		t = strings.ToLower(t)
+		for _, neg := range negations {
+			if t == neg && !containsWord(title, neg) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func containsWord(words []string, w string) bool {
+	for _, x := range words {
+		if strings.ToLower(x) == w {
+			return true
+		}
+	}
+	return false
+}
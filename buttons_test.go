@@ -0,0 +1,94 @@
+package galice
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/temapavloff/galice/match"
+)
+
+func TestVoiceButtons(t *testing.T) {
+	cli := New(true, true)
+	cli.SetVoiceButtons(match.New())
+
+	var last InputData
+	h := cli.CreateHandler(func(i InputData) (OutputData, error) {
+		last = i
+		r := NewResponse("Какую пиццу?", "", false)
+		r.AddButton("Маргарита", true, "", map[string]int{"pizza": 1})
+		r.AddButton("Пепперони", true, "", map[string]int{"pizza": 2})
+		r.AddButton("Другое меню", true, "", nil)
+		return NewOutput(i, r), nil
+	})
+	send := func(body string) {
+		req, err := http.NewRequest("POST", "/skill", bytes.NewReader([]byte(body)))
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code)
+	}
+
+	send(`{"request": {"command": "пепперони", "type": "SimpleUtterance"}, "session": {"session_id": "1"}}`)
+	require.Equal(t, RequestTypeSimpleUtterance, last.Request.Type)
+
+	send(`{"request": {"command": "давай пеперони", "original_utterance": "Давай пеперони", "type": "SimpleUtterance", "nlu": {"tokens": ["давай", "пеперони"]}}, "session": {"session_id": "1"}}`)
+	require.Equal(t, RequestTypeButtonPressed, last.Request.Type)
+	require.Equal(t, `{"pizza":2}`, string(last.Request.Payload))
+	require.Equal(t, "Давай пеперони", last.Request.OriginalUtterance)
+
+	send(`{"request": {"command": "покажи другое меню", "type": "SimpleUtterance"}, "session": {"session_id": "1"}}`)
+	require.Equal(t, RequestTypeSimpleUtterance, last.Request.Type)
+	require.Equal(t, "другое меню", last.Request.Command)
+
+	send(`{"request": {"command": "другое", "type": "SimpleUtterance"}, "session": {"session_id": "1"}}`)
+	require.Equal(t, "другое", last.Request.Command)
+
+	send(`{"request": {"command": "суши", "type": "SimpleUtterance"}, "session": {"session_id": "1"}}`)
+	require.Equal(t, RequestTypeSimpleUtterance, last.Request.Type)
+	require.Equal(t, "суши", last.Request.Command)
+
+	send(`{"request": {"command": "маргарита", "type": "SimpleUtterance"}, "session": {"session_id": "2"}}`)
+	require.Equal(t, RequestTypeSimpleUtterance, last.Request.Type)
+}
+
+func TestVoiceButtonsAmbiguous(t *testing.T) {
+	cli := New(true, true)
+	cli.SetVoiceButtons(match.New())
+
+	var last InputData
+	h := cli.CreateHandler(func(i InputData) (OutputData, error) {
+		last = i
+		r := NewResponse("Какую пиццу?", "", false)
+		r.AddButton("Пицца Маргарита", true, "", map[string]int{"pizza": 1})
+		r.AddButton("Пицца Пепперони", true, "", map[string]int{"pizza": 2})
+		r.AddButton("Без лука", true, "", map[string]bool{"onion": false})
+		return NewOutput(i, r), nil
+	})
+	send := func(body string) {
+		req, err := http.NewRequest("POST", "/skill", bytes.NewReader([]byte(body)))
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code)
+	}
+
+	send(`{"request": {"command": "меню", "type": "SimpleUtterance"}, "session": {"session_id": "1"}}`)
+
+	// "пиццу" matches both pizzas equally
+	send(`{"request": {"command": "пиццу", "type": "SimpleUtterance"}, "session": {"session_id": "1"}}`)
+	require.Equal(t, RequestTypeSimpleUtterance, last.Request.Type)
+
+	send(`{"request": {"command": "не пиццу маргарита", "type": "SimpleUtterance"}, "session": {"session_id": "1"}}`)
+	require.Equal(t, RequestTypeSimpleUtterance, last.Request.Type)
+
+	send(`{"request": {"command": "без лука", "type": "SimpleUtterance"}, "session": {"session_id": "1"}}`)
+	require.Equal(t, RequestTypeButtonPressed, last.Request.Type)
+	require.Equal(t, `{"onion":false}`, string(last.Request.Payload))
+
+	// one of two words is below ButtonsThreshold
+	send(`{"request": {"command": "маргариту", "type": "SimpleUtterance"}, "session": {"session_id": "1"}}`)
+	require.Equal(t, RequestTypeSimpleUtterance, last.Request.Type)
+}
@@ -0,0 +1,48 @@
+// Command galice-assets uploads skill images and sounds listed in a manifest
+// to Yandex Dialogs API and generates Go file with constants of their IDs.
+//
+// Manifest is a JSON file like:
+//
+//	{
+//	    "skill_id": "...",
+//	    "package": "assets",
+//	    "output": "assets/assets.go",
+//	    "lock": "assets.lock.json",
+//	    "images": {"logo": "images/logo.png"},
+//	    "sounds": {"jingle": "sounds/jingle.opus"}
+//	}
+//
+// Paths are relative to the manifest directory. Hashes and IDs of uploaded files
+// are kept in the lock file, so only new and changed files are uploaded, and files
+// removed from the manifest or replaced with new versions are deleted. Files are deleted
+// only after the lock file and generated code are written; if upload fails, nothing is
+// deleted and uploaded files are saved to the lock file.
+// For the manifest above galice-assets generates constants LogoImage and JingleSound.
+//
+// Usage:
+//
+//	DIALOGS_TOKEN=... galice-assets -manifest assets.json [-dry-run]
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/temapavloff/galice"
+)
+
+func main() {
+	var o options
+	flag.StringVar(&o.manifest, "manifest", "assets.json", "path to assets manifest")
+	flag.StringVar(&o.token, "token", os.Getenv("DIALOGS_TOKEN"), "OAuth token for Dialogs API, $DIALOGS_TOKEN by default")
+	flag.StringVar(&o.baseURL, "base-url", galice.DefaultDialogsURL, "base URL of Dialogs API")
+	flag.BoolVar(&o.dryRun, "dry-run", false, "print planned changes without uploading, deleting or writing files")
+	flag.Parse()
+
+	if err := run(context.Background(), o, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,387 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/temapavloff/galice"
+)
+
+// options are command line options
+type options struct {
+	manifest string
+	token    string
+	baseURL  string
+	dryRun   bool
+}
+
+// manifest lists assets of skill, keys of Images and Sounds are asset names
+type manifest struct {
+	SkillID string            `json:"skill_id"`
+	Package string            `json:"package"`
+	Output  string            `json:"output"`
+	Lock    string            `json:"lock"`
+	Images  map[string]string `json:"images"`
+	Sounds  map[string]string `json:"sounds"`
+}
+
+// lockEntry is an uploaded asset
+type lockEntry struct {
+	File   string `json:"file"`
+	SHA256 string `json:"sha256"`
+	ID     string `json:"id"`
+}
+
+// lockFile keeps uploaded assets between runs
+type lockFile struct {
+	Images map[string]lockEntry `json:"images"`
+	Sounds map[string]lockEntry `json:"sounds"`
+}
+
+// assetKind is a set of Dialogs API methods for images or sounds
+type assetKind struct {
+	name   string // used in messages
+	suffix string // suffix of generated constants
+	idType string // type of generated constants
+	list   func(ctx context.Context) ([]string, error)
+	upload func(ctx context.Context, filename string) (string, error)
+	delete func(ctx context.Context, id string) error
+}
+
+type syncer struct {
+	dir    string // manifest directory
+	dryRun bool
+	out    io.Writer
+}
+
+func run(ctx context.Context, o options, out io.Writer) error {
+	m, err := readManifest(o.manifest)
+	if err != nil {
+		return err
+	}
+	if o.token == "" {
+		return fmt.Errorf("OAuth token is not set, use -token flag or DIALOGS_TOKEN environment variable")
+	}
+
+	s := syncer{filepath.Dir(o.manifest), o.dryRun, out}
+	lockPath := filepath.Join(s.dir, m.Lock)
+	lock, err := readLock(lockPath)
+	if err != nil {
+		return err
+	}
+
+	c := galice.NewDialogsClient(m.SkillID, o.token)
+	c.SetBaseURL(o.baseURL)
+
+	images := assetKind{
+		name:   "image",
+		suffix: "Image",
+		idType: "galice.ImageID",
+		list: func(ctx context.Context) ([]string, error) {
+			res, err := c.Images(ctx)
+			ids := make([]string, len(res))
+			for n, img := range res {
+				ids[n] = string(img.ID)
+			}
+			return ids, err
+		},
+		upload: func(ctx context.Context, filename string) (string, error) {
+			img, err := c.UploadImageFile(ctx, filename)
+			return string(img.ID), err
+		},
+		delete: func(ctx context.Context, id string) error {
+			return c.DeleteImage(ctx, galice.ImageID(id))
+		},
+	}
+	sounds := assetKind{
+		name:   "sound",
+		suffix: "Sound",
+		idType: "galice.SoundID",
+		list: func(ctx context.Context) ([]string, error) {
+			res, err := c.Sounds(ctx)
+			ids := make([]string, len(res))
+			for n, snd := range res {
+				ids[n] = string(snd.ID)
+			}
+			return ids, err
+		},
+		upload: func(ctx context.Context, filename string) (string, error) {
+			snd, err := c.UploadSoundFile(ctx, filename)
+			return string(snd.ID), err
+		},
+		delete: func(ctx context.Context, id string) error {
+			return c.DeleteSound(ctx, galice.SoundID(id))
+		},
+	}
+
+	// orphans are deleted only after lock and generated code are written,
+	// so IDs used by deployed code are kept if something fails
+	var newLock lockFile
+	var orphans []orphan
+	newLock.Images, orphans, err = s.sync(ctx, images, m.Images, lock.Images)
+	if err != nil {
+		newLock.Sounds = lock.Sounds
+		return s.saveProgress(lockPath, newLock, err)
+	}
+	var soundOrphans []orphan
+	newLock.Sounds, soundOrphans, err = s.sync(ctx, sounds, m.Sounds, lock.Sounds)
+	if err != nil {
+		return s.saveProgress(lockPath, newLock, err)
+	}
+	orphans = append(orphans, soundOrphans...)
+
+	for _, o := range orphans {
+		fmt.Fprintf(s.out, "delete %v %v (%v)\n", o.kind.name, o.name, o.id)
+	}
+	if s.dryRun {
+		return nil
+	}
+
+	src, err := generate(m.Package, []assetKind{images, sounds}, []map[string]lockEntry{newLock.Images, newLock.Sounds})
+	if err != nil {
+		return err
+	}
+	if err = writeFile(filepath.Join(s.dir, m.Output), src); err != nil {
+		return err
+	}
+	if err = writeLock(lockPath, newLock); err != nil {
+		return err
+	}
+
+	for _, o := range orphans {
+		if err = o.kind.delete(ctx, o.id); err != nil {
+			return fmt.Errorf("Unable to delete %v %v: %v", o.kind.name, o.name, err)
+		}
+	}
+	return nil
+}
+
+// saveProgress writes lock with assets uploaded before sync failed, so they are
+// not uploaded again by the next run. Generated code is not changed.
+func (s syncer) saveProgress(lockPath string, l lockFile, err error) error {
+	if s.dryRun {
+		return err
+	}
+	if lErr := writeLock(lockPath, l); lErr != nil {
+		return fmt.Errorf("%v, unable to save lock file: %v", err, lErr)
+	}
+	return err
+}
+
+func writeLock(filename string, l lockFile) error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFile(filename, append(data, '\n'))
+}
+
+func readManifest(filename string) (manifest, error) {
+	var m manifest
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return m, fmt.Errorf("Unable to read manifest: %v", err)
+	}
+	if err = json.Unmarshal(data, &m); err != nil {
+		return m, fmt.Errorf("Unable to decode manifest %v: %v", filename, err)
+	}
+	if m.SkillID == "" {
+		return m, fmt.Errorf("Skill ID is not set in manifest %v", filename)
+	}
+	for _, names := range []map[string]string{m.Images, m.Sounds} {
+		idents := map[string]string{}
+		for _, name := range sortedKeys(names) {
+			ident, err := identifier(name)
+			if err != nil {
+				return m, err
+			}
+			if other, ok := idents[ident]; ok {
+				return m, fmt.Errorf("Assets %v and %v have the same identifier %v", other, name, ident)
+			}
+			idents[ident] = name
+		}
+	}
+	if m.Package == "" {
+		m.Package = "assets"
+	}
+	if m.Output == "" {
+		m.Output = filepath.Join(m.Package, m.Package+".go")
+	}
+	if m.Lock == "" {
+		m.Lock = "assets.lock.json"
+	}
+	return m, nil
+}
+
+func readLock(filename string) (lockFile, error) {
+	var l lockFile
+	data, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return l, nil
+	}
+	if err != nil {
+		return l, fmt.Errorf("Unable to read lock file: %v", err)
+	}
+	if err = json.Unmarshal(data, &l); err != nil {
+		return l, fmt.Errorf("Unable to decode lock file %v: %v", filename, err)
+	}
+	return l, nil
+}
+
+// orphan is an uploaded asset which was removed from manifest or replaced with new version
+type orphan struct {
+	kind assetKind
+	name string
+	id   string
+}
+
+// sync uploads new and changed assets and returns lock entries of manifest assets and orphans.
+// If upload fails, entries have uploaded assets and old entries of the rest.
+func (s syncer) sync(ctx context.Context, k assetKind, files map[string]string, old map[string]lockEntry) (map[string]lockEntry, []orphan, error) {
+	ids, err := k.list(ctx)
+	if err != nil {
+		return old, nil, fmt.Errorf("Unable to list %vs: %v", k.name, err)
+	}
+	remote := map[string]bool{}
+	for _, id := range ids {
+		remote[id] = true
+	}
+
+	res := map[string]lockEntry{}
+	used := map[string]bool{}
+	for _, name := range sortedKeys(files) {
+		file := files[name]
+		hash, err := fileHash(filepath.Join(s.dir, file))
+		if err != nil {
+			return progress(res, old), nil, err
+		}
+
+		e, ok := old[name]
+		if ok && e.SHA256 == hash && remote[e.ID] {
+			e.File = file
+			res[name] = e
+			used[e.ID] = true
+			continue
+		}
+
+		fmt.Fprintf(s.out, "upload %v %v (%v)\n", k.name, name, file)
+		e = lockEntry{File: file, SHA256: hash}
+		if !s.dryRun {
+			if e.ID, err = k.upload(ctx, filepath.Join(s.dir, file)); err != nil {
+				return progress(res, old), nil, fmt.Errorf("Unable to upload %v %v: %v", k.name, name, err)
+			}
+		}
+		res[name] = e
+		used[e.ID] = true
+	}
+
+	var orphans []orphan
+	for _, name := range sortedKeys(old) {
+		e := old[name]
+		if used[e.ID] || !remote[e.ID] {
+			continue
+		}
+		orphans = append(orphans, orphan{k, name, e.ID})
+	}
+	return res, orphans, nil
+}
+
+// progress merges entries of synced assets with old entries of the rest
+func progress(synced, old map[string]lockEntry) map[string]lockEntry {
+	res := map[string]lockEntry{}
+	for name, e := range old {
+		res[name] = e
+	}
+	for name, e := range synced {
+		res[name] = e
+	}
+	return res
+}
+
+// generate creates Go source with constants of assets IDs
+func generate(pkg string, kinds []assetKind, entries []map[string]lockEntry) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by galice-assets. DO NOT EDIT.\n\npackage %v\n\n", pkg)
+
+	empty := true
+	for _, e := range entries {
+		empty = empty && len(e) == 0
+	}
+	if !empty {
+		fmt.Fprintf(&buf, "import \"github.com/temapavloff/galice\"\n")
+	}
+
+	for n, k := range kinds {
+		if len(entries[n]) == 0 {
+			continue
+		}
+		fmt.Fprintf(&buf, "\n// Uploaded %vs\nconst (\n", k.name)
+		for _, name := range sortedKeys(entries[n]) {
+			ident, err := identifier(name)
+			if err != nil {
+				return nil, err
+			}
+			fmt.Fprintf(&buf, "\t%v%v %v = %q // %v\n", ident, k.suffix, k.idType, entries[n][name].ID, entries[n][name].File)
+		}
+		fmt.Fprintf(&buf, ")\n")
+	}
+	return format.Source(buf.Bytes())
+}
+
+// identifier converts asset name like "main-logo" into exported Go identifier MainLogo
+func identifier(name string) (string, error) {
+	parts := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var b strings.Builder
+	for _, p := range parts {
+		r := []rune(p)
+		b.WriteString(strings.ToUpper(string(r[0])) + string(r[1:]))
+	}
+	ident := b.String()
+	if ident == "" || !unicode.IsUpper([]rune(ident)[0]) {
+		return "", fmt.Errorf("Invalid asset name %q: it must start with a letter", name)
+	}
+	return ident, nil
+}
+
+func fileHash(filename string) (string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return "", fmt.Errorf("Unable to read asset: %v", err)
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("Unable to read asset: %v", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func writeFile(filename string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, data, 0644)
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
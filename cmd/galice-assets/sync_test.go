@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDialogs is an in-memory Dialogs API
+type fakeDialogs struct {
+	mu      sync.Mutex
+	next    int
+	assets  map[string]map[string]string // kind -> ID -> content
+	uploads int
+	deletes int
+	broken  string // kind of assets which cannot be uploaded
+}
+
+func (f *fakeDialogs) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/") // skills/{id}/{kind}[/{assetID}]
+	if len(parts) < 3 || parts[1] != "skill-1" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	kind := parts[2]
+	single := strings.TrimSuffix(kind, "s")
+	switch {
+	case r.Method == http.MethodGet && len(parts) == 3:
+		var list []map[string]string
+		for id := range f.assets[kind] {
+			list = append(list, map[string]string{"id": id})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{kind: list})
+	case r.Method == http.MethodPost && len(parts) == 3 && kind == f.broken:
+		w.WriteHeader(http.StatusInternalServerError)
+	case r.Method == http.MethodPost && len(parts) == 3:
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		data, _ := ioutil.ReadAll(file)
+		f.next++
+		f.uploads++
+		id := fmt.Sprintf("%v-%v", single, f.next)
+		f.assets[kind][id] = string(data)
+		json.NewEncoder(w).Encode(map[string]interface{}{single: map[string]string{"id": id}})
+	case r.Method == http.MethodDelete && len(parts) == 4:
+		if _, ok := f.assets[kind][parts[3]]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		f.deletes++
+		delete(f.assets[kind], parts[3])
+		w.Write([]byte(`{"result":"ok"}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func writeTestFile(t *testing.T, dir, name, content string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+}
+
+func TestSync(t *testing.T) {
+	fake := &fakeDialogs{assets: map[string]map[string]string{"images": {}, "sounds": {}}}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	dir := t.TempDir()
+	writeTestFile(t, dir, "images/logo.png", "logo v1")
+	writeTestFile(t, dir, "sounds/jingle.opus", "jingle")
+	writeTestFile(t, dir, "assets.json", `{"skill_id":"skill-1","images":{"logo":"images/logo.png"},"sounds":{"main-jingle":"sounds/jingle.opus"}}`)
+	o := options{manifest: filepath.Join(dir, "assets.json"), token: "token", baseURL: srv.URL}
+	ctx := context.Background()
+
+	// dry run does not change anything
+	var out bytes.Buffer
+	require.NoError(t, run(ctx, options{o.manifest, o.token, o.baseURL, true}, &out))
+	require.Equal(t, "upload image logo (images/logo.png)\nupload sound main-jingle (sounds/jingle.opus)\n", out.String())
+	require.Equal(t, 0, fake.uploads)
+	_, err := os.Stat(filepath.Join(dir, "assets.lock.json"))
+	require.True(t, os.IsNotExist(err))
+
+	out.Reset()
+	require.NoError(t, run(ctx, o, &out))
+	require.Equal(t, 2, fake.uploads)
+	src, err := ioutil.ReadFile(filepath.Join(dir, "assets/assets.go"))
+	require.NoError(t, err)
+	require.Contains(t, string(src), "package assets")
+	require.Contains(t, string(src), `LogoImage galice.ImageID = "image-1" // images/logo.png`)
+	require.Contains(t, string(src), `MainJingleSound galice.SoundID = "sound-2" // sounds/jingle.opus`)
+
+	// unchanged files are not uploaded again
+	out.Reset()
+	require.NoError(t, run(ctx, o, &out))
+	require.Equal(t, "", out.String())
+	require.Equal(t, 2, fake.uploads)
+
+	// changed file is uploaded, previous version and removed sound are deleted
+	writeTestFile(t, dir, "images/logo.png", "logo v2")
+	writeTestFile(t, dir, "assets.json", `{"skill_id":"skill-1","images":{"logo":"images/logo.png"}}`)
+	out.Reset()
+	require.NoError(t, run(ctx, o, &out))
+	require.Equal(t, "upload image logo (images/logo.png)\ndelete image logo (image-1)\ndelete sound main-jingle (sound-2)\n", out.String())
+	require.Equal(t, map[string]string{"image-3": "logo v2"}, fake.assets["images"])
+	require.Empty(t, fake.assets["sounds"])
+	src, err = ioutil.ReadFile(filepath.Join(dir, "assets/assets.go"))
+	require.NoError(t, err)
+	require.Contains(t, string(src), `LogoImage galice.ImageID = "image-3"`)
+	require.NotContains(t, string(src), "Sound")
+
+	// asset deleted remotely is uploaded again
+	delete(fake.assets["images"], "image-3")
+	require.NoError(t, run(ctx, o, &out))
+	require.Equal(t, 4, fake.uploads)
+}
+
+func TestSyncFailure(t *testing.T) {
+	fake := &fakeDialogs{assets: map[string]map[string]string{"images": {}, "sounds": {}}}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	dir := t.TempDir()
+	writeTestFile(t, dir, "images/logo.png", "logo v1")
+	writeTestFile(t, dir, "sounds/jingle.opus", "jingle v1")
+	writeTestFile(t, dir, "assets.json", `{"skill_id":"skill-1","images":{"logo":"images/logo.png"},"sounds":{"jingle":"sounds/jingle.opus"}}`)
+	o := options{manifest: filepath.Join(dir, "assets.json"), token: "token", baseURL: srv.URL}
+	ctx := context.Background()
+	var out bytes.Buffer
+	require.NoError(t, run(ctx, o, &out))
+	src, err := ioutil.ReadFile(filepath.Join(dir, "assets/assets.go"))
+	require.NoError(t, err)
+
+	// sounds fail after new image is uploaded: nothing is deleted, generated code is kept,
+	// and uploaded image is tracked in lock file
+	writeTestFile(t, dir, "images/logo.png", "logo v2")
+	writeTestFile(t, dir, "sounds/jingle.opus", "jingle v2")
+	fake.mu.Lock()
+	fake.broken = "sounds"
+	fake.mu.Unlock()
+	require.Error(t, run(ctx, o, &out))
+	require.Equal(t, 0, fake.deletes)
+	require.Equal(t, map[string]string{"image-1": "logo v1", "image-3": "logo v2"}, fake.assets["images"])
+	newSrc, err := ioutil.ReadFile(filepath.Join(dir, "assets/assets.go"))
+	require.NoError(t, err)
+	require.Equal(t, string(src), string(newSrc))
+	lock, err := readLock(filepath.Join(dir, "assets.lock.json"))
+	require.NoError(t, err)
+	require.Equal(t, "image-3", lock.Images["logo"].ID)
+	require.Equal(t, "sound-2", lock.Sounds["jingle"].ID)
+
+	// image is not uploaded again
+	fake.mu.Lock()
+	fake.broken = ""
+	fake.mu.Unlock()
+	out.Reset()
+	require.NoError(t, run(ctx, o, &out))
+	require.Equal(t, "upload sound jingle (sounds/jingle.opus)\ndelete sound jingle (sound-2)\n", out.String())
+	require.Equal(t, 4, fake.uploads)
+}
+
+func TestManifestValidation(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "assets.json")
+
+	writeTestFile(t, dir, "assets.json", `{"images":{"logo":"logo.png"}}`)
+	_, err := readManifest(name)
+	require.EqualError(t, err, "Skill ID is not set in manifest "+name)
+
+	writeTestFile(t, dir, "assets.json", `{"skill_id":"1","images":{"1logo":"logo.png"}}`)
+	_, err = readManifest(name)
+	require.EqualError(t, err, `Invalid asset name "1logo": it must start with a letter`)
+
+	writeTestFile(t, dir, "assets.json", `{"skill_id":"1","images":{"main-logo":"a.png","main_logo":"b.png"}}`)
+	_, err = readManifest(name)
+	require.EqualError(t, err, "Assets main-logo and main_logo have the same identifier MainLogo")
+}
@@ -0,0 +1,200 @@
+package galice
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/temapavloff/galice/match"
+)
+
+// confirmPrefix is a prefix of pending questions asked by Confirmation
+const confirmPrefix = "galice.confirm:"
+
+// DefaultReask is a phrase added before question when answer to Confirmation is ambiguous
+const DefaultReask = "Пожалуйста, ответьте да или нет."
+
+var (
+	yesWords = map[string]bool{
+		"да": true, "ага": true, "угу": true, "конечно": true, "давай": true, "давайте": true,
+		"хорошо": true, "ладно": true, "согласен": true, "согласна": true, "подтверждаю": true,
+		"верно": true, "точно": true, "именно": true, "ок": true, "окей": true, "yes": true,
+	}
+	noWords = map[string]bool{
+		"нет": true, "неа": true, "не": true, "отмена": true, "отмени": true, "отменить": true,
+		"стоп": true, "отказываюсь": true, "никогда": true, "no": true,
+	}
+	// doubtPhrases are answers which are neither yes nor no
+	doubtPhrases = [][]string{{"не", "знаю"}, {"может", "быть"}, {"наверное"}, {"возможно"}, {"не", "уверен"}, {"не", "уверена"}}
+)
+
+// YesNo recognizes answer to yes/no question: YANDEX.CONFIRM and YANDEX.REJECT intents,
+// "Да" and "Нет" buttons and common russian phrasings like "конечно" or "не надо".
+// The second result is false if answer is ambiguous.
+func (r *Request) YesNo() (yes bool, ok bool) {
+	confirm, reject := r.NLU.HasIntent(IntentConfirm), r.NLU.HasIntent(IntentReject)
+	if confirm != reject {
+		return confirm, true
+	}
+
+	tokens := r.NLU.Tokens
+	if len(tokens) == 0 {
+		tokens = match.Tokenize(r.Command)
+	}
+	for _, phrase := range doubtPhrases {
+		if containsPhrase(tokens, phrase) {
+			return false, false
+		}
+	}
+
+	var hasYes, hasNo bool
+	for _, t := range tokens {
+		t = strings.ToLower(t)
+		hasYes = hasYes || yesWords[t]
+		hasNo = hasNo || noWords[t]
+	}
+	if hasYes == hasNo {
+		return false, false
+	}
+	return hasYes, true
+}
+
+func containsPhrase(tokens, phrase []string) bool {
+	for i := 0; i+len(phrase) <= len(tokens); i++ {
+		found := true
+		for j, w := range phrase {
+			if strings.ToLower(tokens[i+j]) != w {
+				found = false
+				break
+			}
+		}
+		if found {
+			return true
+		}
+	}
+	return false
+}
+
+// Confirmation is a yes/no question with handlers of answers, created with Client.Confirm
+type Confirmation struct {
+	key   string
+	reask string
+	onYes AliceHandler
+	onNo  AliceHandler
+}
+
+// pendingConfirmation is a question asked with Confirmation.Ask, kept in Client storage
+type pendingConfirmation struct {
+	Key      string          `json:"key"`
+	Question string          `json:"question"`
+	Args     json.RawMessage `json:"args,omitempty"`
+	Retry    bool            `json:"retry,omitempty"`
+}
+
+// Confirm registers handlers of answers to yes/no questions. Key identifies confirmation
+// between requests, so it must be unique and stable across restarts. Confirm is safe to call
+// concurrently with handling requests, but confirmations are expected to be registered on startup,
+// otherwise answers may come to the instance which does not know the key yet.
+// Answer is recognized with Request.YesNo. If answer is ambiguous, question is asked once more,
+// after the second ambiguous answer request is passed to the main handler.
+// Pending question and its arguments are kept in Client storage.
+//
+//	deleteOrder := cli.Confirm("delete_order", onDelete, onKeep)
+//	...
+//	return deleteOrder.Ask(i, "Удалить заказ №42?", order.ID)
+func (c *Client) Confirm(key string, onYes, onNo AliceHandler) *Confirmation {
+	cf := &Confirmation{key, DefaultReask, onYes, onNo}
+	c.confirmationsMu.Lock()
+	defer c.confirmationsMu.Unlock()
+	if c.confirmations == nil {
+		c.confirmations = map[string]*Confirmation{}
+	}
+	c.confirmations[key] = cf
+	return cf
+}
+
+// SetReask sets phrase added before question when answer is ambiguous, DefaultReask is used by default
+func (cf *Confirmation) SetReask(text string) {
+	cf.reask = text
+}
+
+// Ask creates response with question and "Да" and "Нет" buttons. Args are encoded to JSON
+// and kept with pending question, handlers of answers decode them with InputData.ConfirmationArgs.
+func (cf *Confirmation) Ask(i InputData, question string, args interface{}) (OutputData, error) {
+	p := pendingConfirmation{Key: cf.key, Question: question}
+	if args != nil {
+		data, err := json.Marshal(args)
+		if err != nil {
+			return OutputData{}, fmt.Errorf("Unable to encode confirmation args: %v", err)
+		}
+		p.Args = data
+	}
+	return cf.output(i, question, p)
+}
+
+func (cf *Confirmation) output(i InputData, text string, p pendingConfirmation) (OutputData, error) {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return OutputData{}, fmt.Errorf("Unable to save confirmation: %v", err)
+	}
+	r := NewResponse(text, "", false)
+	r.AddButton("Да", true, "", nil)
+	r.AddButton("Нет", true, "", nil)
+	o := NewOutput(i, r)
+	o.AskYesNo(confirmPrefix + string(data))
+	return o, nil
+}
+
+// ConfirmationArgs decodes args passed to Confirmation.Ask into provided variable,
+// it can be used only in handlers of answers to Confirmation
+func (i InputData) ConfirmationArgs(v interface{}) error {
+	if i.confirmationArgs == nil {
+		return fmt.Errorf("Request has no confirmation args")
+	}
+	if err := json.Unmarshal(i.confirmationArgs, v); err != nil {
+		return fmt.Errorf("Unable to decode confirmation args: %v", err)
+	}
+	return nil
+}
+
+// hasConfirmations checks if any Confirmation is registered
+func (c *Client) hasConfirmations() bool {
+	c.confirmationsMu.RLock()
+	defer c.confirmationsMu.RUnlock()
+	return len(c.confirmations) > 0
+}
+
+// routeConfirmation returns handler for answer to pending Confirmation
+// or nil if request should be passed to the main handler
+func (c *Client) routeConfirmation(i InputData, question string) (AliceHandler, error) {
+	var p pendingConfirmation
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(question, confirmPrefix)), &p); err != nil {
+		return nil, fmt.Errorf("Unable to load confirmation: %v", err)
+	}
+	c.confirmationsMu.RLock()
+	cf, ok := c.confirmations[p.Key]
+	c.confirmationsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("Unknown confirmation: %v", p.Key)
+	}
+
+	yes, ok := i.Request.YesNo()
+	switch {
+	case ok:
+		next := cf.onNo
+		if yes {
+			next = cf.onYes
+		}
+		return func(i InputData) (OutputData, error) {
+			i.confirmationArgs = p.Args
+			return next(i)
+		}, nil
+	case !p.Retry:
+		return func(i InputData) (OutputData, error) {
+			retry := p
+			retry.Retry = true
+			return cf.output(i, strings.TrimSpace(cf.reask+" "+p.Question), retry)
+		}, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,120 @@
+package galice
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestYesNo(t *testing.T) {
+	for _, tc := range []struct {
+		request string
+		yes, ok bool
+	}{
+		{`{"command": "конечно", "nlu": {"tokens": ["конечно"]}}`, true, true},
+		{`{"command": "да давай"}`, true, true},
+		{`{"command": "нет не надо"}`, false, true},
+		{`{"command": "отмена"}`, false, true},
+		{`{"command": "не знаю"}`, false, false},
+		{`{"command": "да нет наверное"}`, false, false},
+		{`{"command": "пицца"}`, false, false},
+		{`{"command": "ну", "nlu": {"intents": {"YANDEX.CONFIRM": {"slots": {}}}}}`, true, true},
+		{`{"command": "ну", "nlu": {"intents": {"YANDEX.REJECT": {"slots": {}}}}}`, false, true},
+	} {
+		var r Request
+		require.NoError(t, json.Unmarshal([]byte(tc.request), &r))
+		yes, ok := r.YesNo()
+		require.Equal(t, tc.ok, ok, tc.request)
+		require.Equal(t, tc.yes, yes, tc.request)
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	cli := New(true, true)
+	deleteOrder := cli.Confirm("delete_order", func(i InputData) (OutputData, error) {
+		var id int
+		if err := i.ConfirmationArgs(&id); err != nil {
+			return OutputData{}, err
+		}
+		return NewOutput(i, NewResponse(fmt.Sprintf("Заказ №%v удален", id), "", false)), nil
+	}, func(i InputData) (OutputData, error) {
+		return NewOutput(i, NewResponse("Заказ сохранен", "", false)), nil
+	})
+
+	h := cli.CreateHandler(func(i InputData) (OutputData, error) {
+		if strings.HasPrefix(i.Request.Command, "удали заказ ") {
+			id, err := strconv.Atoi(strings.TrimPrefix(i.Request.Command, "удали заказ "))
+			if err != nil {
+				return OutputData{}, err
+			}
+			return deleteOrder.Ask(i, fmt.Sprintf("Удалить заказ №%v?", id), id)
+		}
+		return NewOutput(i, NewResponse("Что заказать?", "", false)), nil
+	})
+	send := func(session, command string) Response {
+		body := `{"request": {"command": "` + command + `", "type": "SimpleUtterance"}, "session": {"session_id": "` + session + `"}}`
+		req, err := http.NewRequest("POST", "/skill", bytes.NewReader([]byte(body)))
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var o OutputData
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &o))
+		return o.Response
+	}
+
+	r := send("1", "удали заказ 42")
+	require.Equal(t, "Удалить заказ №42?", r.Text)
+	require.Len(t, r.Buttons, 2)
+	require.Equal(t, "Да", r.Buttons[0].Title)
+	require.Equal(t, "Заказ №42 удален", send("1", "да").Text)
+	require.Equal(t, "Что заказать?", send("1", "да").Text)
+
+	// each session has its own question and args
+	send("1", "удали заказ 7")
+	send("2", "удали заказ 8")
+	require.Equal(t, "Пожалуйста, ответьте да или нет. Удалить заказ №7?", send("1", "не знаю").Text)
+	require.Equal(t, "Заказ №8 удален", send("2", "конечно").Text)
+	require.Equal(t, "Заказ №7 удален", send("1", "да").Text)
+
+	send("1", "удали заказ 7")
+	send("1", "не знаю")
+	require.Equal(t, "Заказ сохранен", send("1", "не надо").Text)
+
+	// after the second ambiguous answer request goes to the main handler
+	send("1", "удали заказ 7")
+	send("1", "может быть")
+	require.Equal(t, "Что заказать?", send("1", "пицца").Text)
+	require.Equal(t, "Что заказать?", send("1", "да").Text)
+}
+
+func TestConfirmConcurrent(t *testing.T) {
+	cli := New(true, true)
+	h := cli.CreateHandler(func(i InputData) (OutputData, error) {
+		return NewOutput(i, NewResponse("Что заказать?", "", false)), nil
+	})
+
+	var wg sync.WaitGroup
+	for n := 0; n < 10; n++ {
+		wg.Add(2)
+		go func(n int) {
+			defer wg.Done()
+			cli.Confirm(fmt.Sprintf("confirm_%v", n), nil, nil)
+		}(n)
+		go func() {
+			defer wg.Done()
+			body := `{"request": {"command": "да", "type": "SimpleUtterance"}, "session": {"session_id": "1"}}`
+			req, _ := http.NewRequest("POST", "/skill", bytes.NewReader([]byte(body)))
+			h.ServeHTTP(httptest.NewRecorder(), req)
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,279 @@
+package galice
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+// DefaultDialogsURL is a base URL of Yandex Dialogs API
+const DefaultDialogsURL = "https://dialogs.yandex.net/api/v1"
+
+// MaxSoundSize is a maximum size of sound file accepted by Yandex Dialogs API
+const MaxSoundSize = 1 << 20
+
+// ImageID is an ID of image uploaded to Yandex Dialogs API, used in response cards
+type ImageID string
+
+// SoundID is an ID of sound uploaded to Yandex Dialogs API, used in TTS
+type SoundID string
+
+// Speaker returns TTS tag which plays sound uploaded for skill
+func (id SoundID) Speaker(skillID string) string {
+	return fmt.Sprintf(`<speaker audio="dialogs-upload/%v/%v.opus">`, skillID, id)
+}
+
+// Image is an image uploaded to Yandex Dialogs API
+type Image struct {
+	ID        ImageID   `json:"id"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"createdAt"`
+	OrigURL   string    `json:"origUrl,omitempty"` // URL image was uploaded from
+}
+
+// Sound is a sound uploaded to Yandex Dialogs API
+type Sound struct {
+	ID           SoundID   `json:"id"`
+	SkillID      string    `json:"skillId"`
+	Size         int64     `json:"size"`
+	OriginalName string    `json:"originalName"`
+	CreatedAt    time.Time `json:"createdAt"`
+	IsProcessed  bool      `json:"isProcessed"` // sound can be used only after processing
+	Error        string    `json:"error"`
+}
+
+// Speaker returns TTS tag which plays current sound
+func (s Sound) Speaker() string {
+	return s.ID.Speaker(s.SkillID)
+}
+
+// Quota is an usage of Yandex Dialogs API storage in bytes
+type Quota struct {
+	Total int64 `json:"total"`
+	Used  int64 `json:"used"`
+}
+
+// DialogsStatus is an usage of Yandex Dialogs API storage for images and sounds
+type DialogsStatus struct {
+	Images struct {
+		Quota Quota `json:"quota"`
+	} `json:"images"`
+	Sounds struct {
+		Quota Quota `json:"quota"`
+	} `json:"sounds"`
+}
+
+// DialogsClient is a client of Yandex Dialogs API for managing skill images and sounds
+type DialogsClient struct {
+	skillID    string
+	token      string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewDialogsClient creates new DialogsClient for skill. Token is an OAuth token
+// of skill owner issued for Yandex Dialogs API.
+func NewDialogsClient(skillID, token string) *DialogsClient {
+	return &DialogsClient{
+		skillID:    skillID,
+		token:      token,
+		baseURL:    DefaultDialogsURL,
+		httpClient: http.DefaultClient,
+	}
+}
+
+// SetHTTPClient sets HTTP client used for API requests. If not called http.DefaultClient is used.
+func (c *DialogsClient) SetHTTPClient(client *http.Client) {
+	c.httpClient = client
+}
+
+// SetBaseURL sets base URL of API. If not called DefaultDialogsURL is used.
+func (c *DialogsClient) SetBaseURL(URL string) {
+	c.baseURL = URL
+}
+
+// SkillID returns ID of skill managed by current client
+func (c *DialogsClient) SkillID() string {
+	return c.skillID
+}
+
+// Status returns usage of images and sounds storage
+func (c *DialogsClient) Status(ctx context.Context) (DialogsStatus, error) {
+	var s DialogsStatus
+	err := c.do(ctx, http.MethodGet, "/status", nil, "", &s)
+	return s, err
+}
+
+// UploadImage uploads image read from r, name is an original file name
+func (c *DialogsClient) UploadImage(ctx context.Context, name string, r io.Reader) (Image, error) {
+	var res struct {
+		Image Image `json:"image"`
+	}
+	err := c.upload(ctx, c.skillPath("images"), name, r, &res)
+	return res.Image, err
+}
+
+// UploadImageFile uploads image from local file
+func (c *DialogsClient) UploadImageFile(ctx context.Context, filename string) (Image, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return Image{}, err
+	}
+	defer f.Close()
+	return c.UploadImage(ctx, filepath.Base(filename), f)
+}
+
+// UploadImageURL makes Yandex Dialogs API download image from URL
+func (c *DialogsClient) UploadImageURL(ctx context.Context, URL string) (Image, error) {
+	body, err := json.Marshal(map[string]string{"url": URL})
+	if err != nil {
+		return Image{}, err
+	}
+	var res struct {
+		Image Image `json:"image"`
+	}
+	err = c.do(ctx, http.MethodPost, c.skillPath("images"), bytes.NewReader(body), "application/json", &res)
+	return res.Image, err
+}
+
+// Images returns all images uploaded for skill
+func (c *DialogsClient) Images(ctx context.Context) ([]Image, error) {
+	var res struct {
+		Images []Image `json:"images"`
+	}
+	err := c.do(ctx, http.MethodGet, c.skillPath("images"), nil, "", &res)
+	return res.Images, err
+}
+
+// DeleteImage deletes uploaded image
+func (c *DialogsClient) DeleteImage(ctx context.Context, id ImageID) error {
+	return c.do(ctx, http.MethodDelete, c.skillPath("images/"+string(id)), nil, "", nil)
+}
+
+// UploadSound uploads sound read from r, name is an original file name
+func (c *DialogsClient) UploadSound(ctx context.Context, name string, r io.Reader) (Sound, error) {
+	var res struct {
+		Sound Sound `json:"sound"`
+	}
+	err := c.upload(ctx, c.skillPath("sounds"), name, r, &res)
+	return res.Sound, err
+}
+
+// UploadSoundFile uploads sound from local file
+func (c *DialogsClient) UploadSoundFile(ctx context.Context, filename string) (Sound, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return Sound{}, err
+	}
+	defer f.Close()
+	return c.UploadSound(ctx, filepath.Base(filename), f)
+}
+
+// UploadSoundURL downloads sound from URL and uploads it to Yandex Dialogs API.
+// Sounds larger than MaxSoundSize are rejected without uploading.
+func (c *DialogsClient) UploadSoundURL(ctx context.Context, URL string) (Sound, error) {
+	req, err := http.NewRequest(http.MethodGet, URL, nil)
+	if err != nil {
+		return Sound{}, err
+	}
+	resp, err := c.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return Sound{}, fmt.Errorf("Unable to download sound: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Sound{}, fmt.Errorf("Unable to download sound: status %v", resp.StatusCode)
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, MaxSoundSize+1))
+	if err != nil {
+		return Sound{}, fmt.Errorf("Unable to download sound: %v", err)
+	}
+	if len(data) > MaxSoundSize {
+		return Sound{}, fmt.Errorf("Unable to download sound: size exceeds %v bytes", MaxSoundSize)
+	}
+	return c.UploadSound(ctx, path.Base(req.URL.Path), bytes.NewReader(data))
+}
+
+// Sounds returns all sounds uploaded for skill
+func (c *DialogsClient) Sounds(ctx context.Context) ([]Sound, error) {
+	var res struct {
+		Sounds []Sound `json:"sounds"`
+	}
+	err := c.do(ctx, http.MethodGet, c.skillPath("sounds"), nil, "", &res)
+	return res.Sounds, err
+}
+
+// DeleteSound deletes uploaded sound
+func (c *DialogsClient) DeleteSound(ctx context.Context, id SoundID) error {
+	return c.do(ctx, http.MethodDelete, c.skillPath("sounds/"+string(id)), nil, "", nil)
+}
+
+func (c *DialogsClient) skillPath(p string) string {
+	return fmt.Sprintf("/skills/%v/%v", c.skillID, p)
+}
+
+func (c *DialogsClient) upload(ctx context.Context, p, name string, r io.Reader, res interface{}) error {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	part, err := w.CreateFormFile("file", name)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(part, r); err != nil {
+		return fmt.Errorf("Unable to read %v: %v", name, err)
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+	return c.do(ctx, http.MethodPost, p, &buf, w.FormDataContentType(), res)
+}
+
+func (c *DialogsClient) do(ctx context.Context, method, p string, body io.Reader, contentType string, res interface{}) error {
+	req, err := http.NewRequest(method, c.baseURL+p, body)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Authorization", "OAuth "+c.token)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("Dialogs API error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("Dialogs API error: %v", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var e struct {
+			Message string `json:"message"`
+		}
+		msg := string(data)
+		if json.Unmarshal(data, &e) == nil && e.Message != "" {
+			msg = e.Message
+		}
+		return fmt.Errorf("Dialogs API error: status %v: %v", resp.StatusCode, msg)
+	}
+
+	if res == nil {
+		return nil
+	}
+	if err = json.Unmarshal(data, res); err != nil {
+		return fmt.Errorf("Error decoding Dialogs API response: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package galice
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newDialogsServer(t *testing.T, h http.HandlerFunc) *DialogsClient {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "OAuth token", r.Header.Get("Authorization"))
+		h(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	c := NewDialogsClient("skill-1", "token")
+	c.SetHTTPClient(srv.Client())
+	c.SetBaseURL(srv.URL)
+	return c
+}
+
+func TestDialogsUploadImage(t *testing.T) {
+	c := newDialogsServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/skills/skill-1/images", r.URL.Path)
+		if r.Header.Get("Content-Type") == "application/json" {
+			var body map[string]string
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			w.Write([]byte(`{"image":{"id":"2","size":10,"createdAt":"2019-03-21T12:00:00.000Z","origUrl":"` + body["url"] + `"}}`))
+			return
+		}
+		f, h, err := r.FormFile("file")
+		if !assert.NoError(t, err) {
+			return
+		}
+		data, _ := ioutil.ReadAll(f)
+		assert.Equal(t, "logo.png", h.Filename)
+		assert.Equal(t, "png data", string(data))
+		w.Write([]byte(`{"image":{"id":"1","size":8,"createdAt":"2019-03-21T12:00:00.000Z"}}`))
+	})
+
+	name := filepath.Join(t.TempDir(), "logo.png")
+	require.NoError(t, os.WriteFile(name, []byte("png data"), 0644))
+	img, err := c.UploadImageFile(context.Background(), name)
+	require.NoError(t, err)
+	require.Equal(t, ImageID("1"), img.ID)
+	require.Equal(t, int64(8), img.Size)
+	require.Equal(t, 2019, img.CreatedAt.Year())
+
+	img, err = c.UploadImageURL(context.Background(), "https://example.com/logo.png")
+	require.NoError(t, err)
+	require.Equal(t, ImageID("2"), img.ID)
+	require.Equal(t, "https://example.com/logo.png", img.OrigURL)
+}
+
+func TestDialogsSounds(t *testing.T) {
+	c := newDialogsServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "POST /skills/skill-1/sounds":
+			f, h, err := r.FormFile("file")
+			if !assert.NoError(t, err) {
+				return
+			}
+			data, _ := ioutil.ReadAll(f)
+			assert.Equal(t, "jingle.opus", h.Filename)
+			assert.Equal(t, "opus data", string(data))
+			w.Write([]byte(`{"sound":{"id":"s1","skillId":"skill-1","size":9,"originalName":"jingle.opus","isProcessed":false,"error":null}}`))
+		case "GET /skills/skill-1/sounds":
+			w.Write([]byte(`{"sounds":[{"id":"s1","skillId":"skill-1","isProcessed":true}],"total":1}`))
+		case "DELETE /skills/skill-1/sounds/s1":
+			w.Write([]byte(`{"result":"ok"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"Resource not found"}`))
+		}
+	})
+
+	ctx := context.Background()
+	snd, err := c.UploadSound(ctx, "jingle.opus", strings.NewReader("opus data"))
+	require.NoError(t, err)
+	require.Equal(t, SoundID("s1"), snd.ID)
+	require.Equal(t, `<speaker audio="dialogs-upload/skill-1/s1.opus">`, snd.Speaker())
+
+	sounds, err := c.Sounds(ctx)
+	require.NoError(t, err)
+	require.Len(t, sounds, 1)
+	require.True(t, sounds[0].IsProcessed)
+
+	files := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/large.opus" {
+			w.Write(make([]byte, MaxSoundSize+1))
+			return
+		}
+		w.Write([]byte("opus data"))
+	}))
+	defer files.Close()
+	snd, err = c.UploadSoundURL(ctx, files.URL+"/jingle.opus")
+	require.NoError(t, err)
+	require.Equal(t, SoundID("s1"), snd.ID)
+	_, err = c.UploadSoundURL(ctx, files.URL+"/large.opus")
+	require.EqualError(t, err, "Unable to download sound: size exceeds 1048576 bytes")
+
+	require.NoError(t, c.DeleteSound(ctx, "s1"))
+	err = c.DeleteSound(ctx, "s2")
+	require.EqualError(t, err, "Dialogs API error: status 404: Resource not found")
+}
+
+func TestDialogsStatus(t *testing.T) {
+	c := newDialogsServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/status", r.URL.Path)
+		w.Write([]byte(`{"images":{"quota":{"total":104857600,"used":1024}},"sounds":{"quota":{"total":1073741824,"used":0}}}`))
+	})
+
+	s, err := c.Status(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, Quota{104857600, 1024}, s.Images.Quota)
+	require.Equal(t, Quota{1073741824, 0}, s.Sounds.Quota)
+}
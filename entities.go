@@ -3,6 +3,9 @@ package galice
 import (
 	"encoding/json"
 	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -24,7 +27,8 @@ type Session struct {
 	UserID    string `json:"user_id"`    // ID of current user
 }
 
-// RequestType represents type of Alice API request: SimpleUtterance or ButtonPressed
+// RequestType represents type of Alice API request: SimpleUtterance, ButtonPressed,
+// Purchase.Confirmation or Show.Pull
 type RequestType uint8
 
 // MarshalJSON converts inner representation to values supported by Alice API
@@ -35,6 +39,12 @@ func (r RequestType) MarshalJSON() ([]byte, error) {
 	if r == RequestTypeButtonPressed {
 		return []byte("\"ButtonPressed\""), nil
 	}
+	if r == RequestTypePurchaseConfirmation {
+		return []byte("\"Purchase.Confirmation\""), nil
+	}
+	if r == RequestTypeShowPull {
+		return []byte("\"Show.Pull\""), nil
+	}
 
 	return []byte{}, fmt.Errorf("Unsupported RequestType value: %v", r)
 }
@@ -50,6 +60,14 @@ func (r *RequestType) UnmarshalJSON(input []byte) error {
 		*r = RequestTypeButtonPressed
 		return nil
 	}
+	if str == "\"Purchase.Confirmation\"" {
+		*r = RequestTypePurchaseConfirmation
+		return nil
+	}
+	if str == "\"Show.Pull\"" {
+		*r = RequestTypeShowPull
+		return nil
+	}
 
 	return fmt.Errorf("Unsupported RequestType value: %v", str)
 }
@@ -59,6 +77,10 @@ const (
 	RequestTypeSimpleUtterance = RequestType(iota)
 	// RequestTypeButtonPressed represents ButtonPressed request type
 	RequestTypeButtonPressed
+	// RequestTypePurchaseConfirmation represents Purchase.Confirmation request type
+	RequestTypePurchaseConfirmation
+	// RequestTypeShowPull represents Show.Pull request type
+	RequestTypeShowPull
 )
 
 // RequestMarkup is Alice API request markup metadatas
@@ -68,7 +90,8 @@ type RequestMarkup struct {
 }
 
 // EntityType is a type of ALice API request named entity:
-// YANDEX.DATETIME, YANDEX.FIO, YANDEX.GEO, YANDEX.NUMBER
+// YANDEX.DATETIME, YANDEX.FIO, YANDEX.GEO, YANDEX.NUMBER.
+// All other types (YANDEX.STRING, custom entities, etc.) are EntityTypeUnknown.
 type EntityType uint8
 
 const (
@@ -80,8 +103,27 @@ const (
 	EntityTypeGeo
 	// EntityTypeNumber represents YANDEX.NUMBER
 	EntityTypeNumber
+	// EntityTypeUnknown represents any other entity type, its name is kept in RequestEntity.TypeName
+	EntityTypeUnknown
 )
 
+// String returns Alice API name of entity type, e.g. "YANDEX.NUMBER"
+func (e EntityType) String() string {
+	switch e {
+	case EntityTypeDateTime:
+		return "YANDEX.DATETIME"
+	case EntityTypeFIO:
+		return "YANDEX.FIO"
+	case EntityTypeGeo:
+		return "YANDEX.GEO"
+	case EntityTypeNumber:
+		return "YANDEX.NUMBER"
+	case EntityTypeUnknown:
+		return "unknown"
+	}
+	return fmt.Sprintf("EntityType(%d)", uint8(e))
+}
+
 // MarshalJSON converts inner representation to values supported by Alice API
 func (e EntityType) MarshalJSON() ([]byte, error) {
 	if e == EntityTypeDateTime {
@@ -120,7 +162,12 @@ func (e *EntityType) UnmarshalJSON(input []byte) error {
 		return nil
 	}
 
-	return fmt.Errorf("Unsupported EntityType value: %v", str)
+	var name string
+	if err := json.Unmarshal(input, &name); err != nil {
+		return fmt.Errorf("Unsupported EntityType value: %v", str)
+	}
+	*e = EntityTypeUnknown
+	return nil
 }
 
 // ValueFIO is a value type for entities contains information of
@@ -154,6 +201,77 @@ type ValueDateTime struct {
 	HourIsRelative   bool `json:"hour_is_relative"`
 	Minute           int  `json:"minute"`
 	MinuteIsRelative bool `json:"minute_is_relative"`
+
+	present uint8 // fields sent by Alice API, see HasYear, HasMonth, etc.
+}
+
+// Fields of ValueDateTime sent by Alice API
+const (
+	dateTimeYear = 1 << iota
+	dateTimeMonth
+	dateTimeDay
+	dateTimeHour
+	dateTimeMinute
+)
+
+// UnmarshalJSON decodes ValueDateTime keeping information about fields sent by Alice API
+func (v *ValueDateTime) UnmarshalJSON(input []byte) error {
+	type plain ValueDateTime
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(input, (*plain)(v)); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(input, &fields); err != nil {
+		return err
+	}
+
+	v.present = 0
+	for name, bit := range map[string]uint8{
+		"year":   dateTimeYear,
+		"month":  dateTimeMonth,
+		"day":    dateTimeDay,
+		"hour":   dateTimeHour,
+		"minute": dateTimeMinute,
+	} {
+		if _, ok := fields[name]; ok {
+			v.present |= bit
+		}
+	}
+	return nil
+}
+
+// has checks if field was sent by Alice API. For values created
+// without decoding fields with non-zero or relative values are treated as sent.
+func (v *ValueDateTime) has(bit uint8, value int, relative bool) bool {
+	if v.present != 0 {
+		return v.present&bit != 0
+	}
+	return value != 0 || relative
+}
+
+// HasYear checks if year is specified in ValueDateTime
+func (v *ValueDateTime) HasYear() bool {
+	return v.has(dateTimeYear, v.Year, v.YearIsRelative)
+}
+
+// HasMonth checks if month is specified in ValueDateTime
+func (v *ValueDateTime) HasMonth() bool {
+	return v.has(dateTimeMonth, v.Month, v.MonthIsRelative)
+}
+
+// HasDay checks if day is specified in ValueDateTime
+func (v *ValueDateTime) HasDay() bool {
+	return v.has(dateTimeDay, v.Day, v.DayIsRelative)
+}
+
+// HasHour checks if hour is specified in ValueDateTime, e.g. hour 0 is midnight only if HasHour is true
+func (v *ValueDateTime) HasHour() bool {
+	return v.has(dateTimeHour, v.Hour, v.HourIsRelative)
+}
+
+// HasMinute checks if minute is specified in ValueDateTime
+func (v *ValueDateTime) HasMinute() bool {
+	return v.has(dateTimeMinute, v.Minute, v.MinuteIsRelative)
 }
 
 // IsRelative return trus if ValueDateTime is in relative format, false otherwise
@@ -182,14 +300,98 @@ func (v *ValueDateTime) Time(zoneID string) (time.Time, error) {
 	return time.Date(v.Year, time.Month(v.Month), v.Day, v.Hour, v.Minute, 0, 0, location), nil
 }
 
+// ValueNumber is a value of YANDEX.NUMBER entity. It keeps number as sent by
+// Alice API, so large and exponent formatted values are not rounded.
+type ValueNumber json.Number
+
+// UnmarshalJSON decodes JSON number into ValueNumber
+func (v *ValueNumber) UnmarshalJSON(input []byte) error {
+	var n json.Number
+	if err := json.Unmarshal(input, &n); err != nil {
+		return err
+	}
+	if _, ok := new(big.Rat).SetString(string(n)); !ok {
+		return fmt.Errorf("Invalid number value: %v", string(input))
+	}
+	*v = ValueNumber(n)
+	return nil
+}
+
+// String returns number as sent by Alice API
+func (v ValueNumber) String() string {
+	return string(v)
+}
+
+// IsInteger checks if number has no fractional part, e.g. 16, -3 or 1e3
+func (v ValueNumber) IsInteger() bool {
+	r, ok := new(big.Rat).SetString(string(v))
+	return ok && r.IsInt()
+}
+
+// Int64 returns integer number or error if number is not integer or overflows int64
+func (v ValueNumber) Int64() (int64, error) {
+	r, ok := new(big.Rat).SetString(string(v))
+	if !ok || !r.IsInt() {
+		return 0, fmt.Errorf("Number %v is not integer", v)
+	}
+	if !r.Num().IsInt64() {
+		return 0, fmt.Errorf("Number %v overflows int64", v)
+	}
+	return r.Num().Int64(), nil
+}
+
+// Float64 returns number as float64, it may lose precision
+func (v ValueNumber) Float64() (float64, error) {
+	f, err := strconv.ParseFloat(string(v), 64)
+	if err != nil {
+		return 0, fmt.Errorf("Number %v cannot be represented as float64: %v", v, err)
+	}
+	return f, nil
+}
+
 // RequestEntity is a representation of Alice API request named entity
 type RequestEntity struct {
 	Tokens struct {
 		Start uint `json:"start"`
 		End   uint `json:"end"`
 	} `json:"tokens"`
-	Type  EntityType      `json:"type"`
-	Value json.RawMessage `json:"value"`
+	Type     EntityType      `json:"type"`
+	TypeName string          `json:"-"` // type as sent by Alice API, e.g. "YANDEX.STRING" for EntityTypeUnknown
+	Value    json.RawMessage `json:"value"`
+}
+
+// requestEntityJSON is a RequestEntity without JSON methods
+type requestEntityJSON RequestEntity
+
+// UnmarshalJSON decodes RequestEntity keeping name of its type
+func (e *RequestEntity) UnmarshalJSON(input []byte) error {
+	var name struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(input, (*requestEntityJSON)(e)); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(input, &name); err != nil {
+		return err
+	}
+	e.TypeName = name.Type
+	return nil
+}
+
+// MarshalJSON encodes RequestEntity, TypeName is used as type of EntityTypeUnknown entities
+func (e RequestEntity) MarshalJSON() ([]byte, error) {
+	if e.Type != EntityTypeUnknown {
+		return json.Marshal(requestEntityJSON(e))
+	}
+	return json.Marshal(struct {
+		requestEntityJSON
+		Type string `json:"type"`
+	}{requestEntityJSON(e), e.TypeName})
+}
+
+// IsUnknown checks if RequestEntity has type not supported by galice, see TypeName for its name
+func (e *RequestEntity) IsUnknown() bool {
+	return e.Type == EntityTypeUnknown
 }
 
 // IsFIO checks if RequestEntity is YANDEX.FIO
@@ -202,14 +404,17 @@ func (e *RequestEntity) IsGeo() bool {
 	return e.Type == EntityTypeGeo
 }
 
-// IsFloat checks if RequestEntity is floating point YANDEX.NUMBER
+// IsFloat checks if RequestEntity is floating point YANDEX.NUMBER: number with decimal
+// point (1.5 or 1.0) or with fractional part (25e-1)
 func (e *RequestEntity) IsFloat() bool {
-	return e.Type == EntityTypeNumber && isJSONNumberIsFloat(e.Value)
+	v, err := e.NumberValue()
+	return err == nil && (strings.Contains(string(v), ".") || !v.IsInteger())
 }
 
-// IsInt checks if RequestEntity is integer YANDEX.NUMBER
+// IsInt checks if RequestEntity is integer YANDEX.NUMBER: number without decimal point (16 or 1e3)
 func (e *RequestEntity) IsInt() bool {
-	return e.Type == EntityTypeNumber && !isJSONNumberIsFloat(e.Value)
+	v, err := e.NumberValue()
+	return err == nil && !strings.Contains(string(v), ".") && v.IsInteger()
 }
 
 // IsDateTime checks if RequestEntity is YANDEX.DATETIME
@@ -247,16 +452,12 @@ func (e *RequestEntity) GeoValue() (ValueGeo, error) {
 	return v, nil
 }
 
-// FloatValue returns float if RequestEntity is floating point YANDEX.NUMBER or error otherwhise
-func (e *RequestEntity) FloatValue() (float64, error) {
-	var v float64
+// NumberValue returns ValueNumber if RequestEntity is YANDEX.NUMBER or error otherwhise
+func (e *RequestEntity) NumberValue() (ValueNumber, error) {
+	var v ValueNumber
 
-	if !e.IsFloat() {
-		var add string
-		if e.IsInt() {
-			add = ", integer"
-		}
-		return v, fmt.Errorf("Cannot create float for entity type %v%v", e.Type, add)
+	if e.Type != EntityTypeNumber {
+		return v, fmt.Errorf("Cannot create ValueNumber for entity type %v", e.Type)
 	}
 
 	if err := json.Unmarshal(e.Value, &v); err != nil {
@@ -266,23 +467,33 @@ func (e *RequestEntity) FloatValue() (float64, error) {
 	return v, nil
 }
 
+// FloatValue returns float if RequestEntity is YANDEX.NUMBER (either integer or floating point)
+// or error otherwhise
+func (e *RequestEntity) FloatValue() (float64, error) {
+	v, err := e.NumberValue()
+	if err != nil {
+		return 0, err
+	}
+	return v.Float64()
+}
+
 // IntValue returns integer if RequestEntity is integer YANDEX.NUMBER or error otherwhise
 func (e *RequestEntity) IntValue() (int, error) {
-	var v int
-
+	v, err := e.NumberValue()
+	if err != nil {
+		return 0, err
+	}
 	if !e.IsInt() {
-		var add string
-		if e.IsFloat() {
-			add = ", float"
-		}
-		return v, fmt.Errorf("Cannot create integer for entity type %v%v", e.Type, add)
+		return 0, fmt.Errorf("Cannot create integer for entity type %v, float", e.Type)
 	}
-
-	if err := json.Unmarshal(e.Value, &v); err != nil {
-		return v, err
+	i, err := v.Int64()
+	if err != nil {
+		return 0, err
 	}
-
-	return v, nil
+	if int64(int(i)) != i {
+		return 0, fmt.Errorf("Number %v overflows int", v)
+	}
+	return int(i), nil
 }
 
 // DateTimeValue returns time.Time if RequestEntity is YANDEX.DATETIME or error otherwhise
@@ -302,8 +513,101 @@ func (e *RequestEntity) DateTimeValue() (ValueDateTime, error) {
 
 // RequestNLU is a struct contains words and names entities of Alice API request
 type RequestNLU struct {
-	Tokens   []string        `json:"tokens"`
-	Entities []RequestEntity `json:"entities"`
+	Tokens   []string          `json:"tokens"`
+	Entities []RequestEntity   `json:"entities"`
+	Intents  map[string]Intent `json:"intents,omitempty"` // intents recognized by Alice NLU, keyed by name
+}
+
+// EntityTokens returns words of request which entity was extracted from
+func (n *RequestNLU) EntityTokens(e RequestEntity) []string {
+	start, end := n.span(e)
+	return n.Tokens[start:end]
+}
+
+// EntityText returns words of request which entity was extracted from joined with spaces
+func (n *RequestNLU) EntityText(e RequestEntity) string {
+	return strings.Join(n.EntityTokens(e), " ")
+}
+
+// EntitiesOfType returns all entities of provided type in order of their appearance in request
+func (n *RequestNLU) EntitiesOfType(t EntityType) []RequestEntity {
+	var res []RequestEntity
+	for _, e := range n.Entities {
+		if e.Type == t {
+			res = append(res, e)
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Tokens.Start < res[j].Tokens.Start
+	})
+	return res
+}
+
+// CommandWithout returns request words except the ones entities of provided types were
+// extracted from, e.g. "напомни позвонить маме" for "напомни завтра позвонить маме"
+// without EntityTypeDateTime
+func (n *RequestNLU) CommandWithout(types ...EntityType) string {
+	skip := make([]bool, len(n.Tokens))
+	for _, e := range n.Entities {
+		for _, t := range types {
+			if e.Type != t {
+				continue
+			}
+			start, end := n.span(e)
+			for i := start; i < end; i++ {
+				skip[i] = true
+			}
+		}
+	}
+
+	var words []string
+	for i, token := range n.Tokens {
+		if !skip[i] {
+			words = append(words, token)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// FirstEntityAfter returns the first entity of provided type which follows keyword in request,
+// e.g. a date after "до" in "с понедельника до пятницы". Keyword may contain several words.
+func (n *RequestNLU) FirstEntityAfter(keyword string, t EntityType) (RequestEntity, bool) {
+	words := strings.Fields(strings.ToLower(keyword))
+	if len(words) == 0 {
+		return RequestEntity{}, false
+	}
+
+	for i := 0; i+len(words) <= len(n.Tokens); i++ {
+		found := true
+		for j, w := range words {
+			if strings.ToLower(n.Tokens[i+j]) != w {
+				found = false
+				break
+			}
+		}
+		if !found {
+			continue
+		}
+		for _, e := range n.EntitiesOfType(t) {
+			if int(e.Tokens.Start) >= i+len(words) {
+				return e, true
+			}
+		}
+		return RequestEntity{}, false
+	}
+	return RequestEntity{}, false
+}
+
+// span returns entity tokens indexes limited by request tokens
+func (n *RequestNLU) span(e RequestEntity) (int, int) {
+	start, end := int(e.Tokens.Start), int(e.Tokens.End)
+	if end > len(n.Tokens) {
+		end = len(n.Tokens)
+	}
+	if start > end {
+		start = end
+	}
+	return start, end
 }
 
 // Request is an Alice request
@@ -321,6 +625,18 @@ type Request struct {
 	Payload json.RawMessage `json:"payload"`
 	// Words and named entities retrieved from user request
 	NLU RequestNLU `json:"nlu"`
+
+	// Purchase.Confirmation request fields
+	PurchaseRequestID string          `json:"purchase_request_id,omitempty"` // ID from PurchaseDirective
+	PurchaseToken     string          `json:"purchase_token,omitempty"`      // purchase ID in Yandex payment system
+	OrderID           string          `json:"order_id,omitempty"`            // order ID in Yandex payment system
+	PurchaseTimestamp int64           `json:"purchase_timestamp,omitempty"`  // unix time of purchase
+	PurchasePayload   json.RawMessage `json:"purchase_payload,omitempty"`    // payload from PurchaseDirective
+	SignedData        string          `json:"signed_data,omitempty"`         // purchase data signed by Yandex
+	Signature         string          `json:"signature,omitempty"`           // base64 encoded signature of SignedData
+
+	// Type of show requested by Show.Pull request, e.g. ShowTypeMorning
+	ShowType string `json:"show_type,omitempty"`
 }
 
 // DecodePayload decodes current request payload into provied variable
@@ -331,6 +647,16 @@ func (r *Request) DecodePayload(p interface{}) error {
 	return nil
 }
 
+// IsPurchaseConfirmation checks if current request is Purchase.Confirmation
+func (r *Request) IsPurchaseConfirmation() bool {
+	return r.Type == RequestTypePurchaseConfirmation
+}
+
+// IsShowPull checks if current request is Show.Pull
+func (r *Request) IsShowPull() bool {
+	return r.Type == RequestTypeShowPull
+}
+
 // IsPing checks if current request is Yandex healthcheck
 func (r *Request) IsPing() bool {
 	return r.OriginalUtterance == "ping"
@@ -341,12 +667,29 @@ func (r *Request) IsDangerousContext() bool {
 	return r.Markup.DangerousContext
 }
 
+// State is a skill data saved by Alice API between requests
+type State struct {
+	Session     json.RawMessage `json:"session"`     // data saved with OutputData.SessionState
+	User        json.RawMessage `json:"user"`        // data saved with OutputData.UserStateUpdate
+	Application json.RawMessage `json:"application"` // data saved with OutputData.ApplicationState
+}
+
 // InputData is an incoming data from Alice API
 type InputData struct {
 	Version string  `json:"version"`
 	Meta    Meta    `json:"meta"`
 	Session Session `json:"session"`
 	Request Request `json:"request"`
+	State   State   `json:"state"`
+
+	fallback         bool            // request is passed to fallback handler, see IsFallback
+	confirmationArgs json.RawMessage // args of answered Confirmation, see ConfirmationArgs
+}
+
+// IsFallback checks if request is handled by fallback handler after AliceHandler failed,
+// it allows middlewares to distinguish fallback responses
+func (i InputData) IsFallback() bool {
+	return i.fallback
 }
 
 // ResponseButton is an Alice API representation of Button for response
@@ -357,12 +700,92 @@ type ResponseButton struct {
 	Payload interface{} `json:"payload,omitempty"`
 }
 
+// CardType represents type of response card: BigImage or ItemsList
+type CardType uint8
+
+const (
+	// CardTypeBigImage represents card with single image
+	CardTypeBigImage = CardType(iota)
+	// CardTypeItemsList represents card with list of images
+	CardTypeItemsList
+)
+
+// MarshalJSON converts inner representation to values supported by Alice API
+func (c CardType) MarshalJSON() ([]byte, error) {
+	if c == CardTypeBigImage {
+		return []byte("\"BigImage\""), nil
+	}
+	if c == CardTypeItemsList {
+		return []byte("\"ItemsList\""), nil
+	}
+
+	return []byte{}, fmt.Errorf("Unsupported CardType value: %v", c)
+}
+
+// UnmarshalJSON converts Alice API type of card into internal CardType value
+func (c *CardType) UnmarshalJSON(input []byte) error {
+	str := string(input)
+	if str == "\"BigImage\"" {
+		*c = CardTypeBigImage
+		return nil
+	}
+	if str == "\"ItemsList\"" {
+		*c = CardTypeItemsList
+		return nil
+	}
+
+	return fmt.Errorf("Unsupported CardType value: %v", str)
+}
+
+// CardButton is a button making card or card item clickable
+type CardButton struct {
+	Text    string      `json:"text,omitempty"`
+	URL     string      `json:"url,omitempty"`
+	Payload interface{} `json:"payload,omitempty"`
+}
+
+// CardItem is an image of ItemsList card
+type CardItem struct {
+	ImageID     ImageID     `json:"image_id"`
+	Title       string      `json:"title,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Button      *CardButton `json:"button,omitempty"`
+}
+
+// CardHeader is a header of ItemsList card
+type CardHeader struct {
+	Text string `json:"text"`
+}
+
+// CardFooter is a footer of ItemsList card
+type CardFooter struct {
+	Text   string      `json:"text"`
+	Button *CardButton `json:"button,omitempty"`
+}
+
+// Card is an Alice API representation of response card. Use image fields
+// for BigImage cards and Header, Items and Footer for ItemsList cards.
+type Card struct {
+	Type        CardType    `json:"type"`
+	ImageID     ImageID     `json:"image_id,omitempty"`
+	Title       string      `json:"title,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Button      *CardButton `json:"button,omitempty"`
+	Header      *CardHeader `json:"header,omitempty"`
+	Items       []CardItem  `json:"items,omitempty"`
+	Footer      *CardFooter `json:"footer,omitempty"`
+}
+
 // Response is an Alice response
 type Response struct {
-	Text       string           `json:"text"`
-	TTS        string           `json:"tts"`
-	Buttons    []ResponseButton `json:"buttons,omitempty"`
-	EndSession bool             `json:"end_session"`
+	Text         string                 `json:"text"`
+	TTS          string                 `json:"tts"`
+	Card         *Card                  `json:"card,omitempty"`
+	Buttons      []ResponseButton       `json:"buttons,omitempty"`
+	EndSession   bool                   `json:"end_session"`
+	ShouldListen *bool                  `json:"should_listen,omitempty"` // should microphone be turned on after response
+	Directives   map[string]interface{} `json:"directives,omitempty"`
+	ShowItemMeta *ShowItemMeta          `json:"show_item_meta,omitempty"` // show content description for Show.Pull requests
 }
 
 // AddButton adds new button into current response
@@ -374,26 +797,46 @@ func (r *Response) AddButton(title string, hide bool, URL string, payload interf
 	r.Buttons = append(r.Buttons, ResponseButton{title, hide, URL, payload})
 }
 
+// TTSFilter transforms TTS of response, e.g. spells numbers or removes unpronounceable symbols
+type TTSFilter func(tts string) string
+
+// FilterTTS applies filters to response TTS. If TTS is empty, response text
+// prepared with default sanitizer is used.
+func (r *Response) FilterTTS(filters ...TTSFilter) {
+	if r.TTS == "" {
+		r.TTS = sanitize(r.Text)
+	}
+	for _, f := range filters {
+		r.TTS = f(r.TTS)
+	}
+}
+
 // NewResponse creates new response. Use text variable to set response text message.
-// Use tts variable to specify text to speach markup, if empty text value will be used.
+// Use tts variable to specify text to speach markup, if empty text value prepared
+// with default sanitizer will be used (see SetDefaultSanitizer).
 // Use endSession flag to specify that current message is the last one in current session.
 func NewResponse(text, tts string, endSession bool) Response {
 	if tts == "" {
-		tts = text
+		tts = sanitize(text)
 	}
 	return Response{
-		text,
-		tts,
-		nil,
-		endSession,
+		Text:       text,
+		TTS:        tts,
+		EndSession: endSession,
 	}
 }
 
 // OutputData is an outcoming data for Alice API
 type OutputData struct {
-	Version  string   `json:"version"`
-	Session  Session  `json:"session"`
-	Response Response `json:"response"`
+	Version          string      `json:"version"`
+	Session          Session     `json:"session"`
+	Response         Response    `json:"response"`
+	SessionState     interface{} `json:"session_state,omitempty"`     // data for State.Session of the next request
+	UserStateUpdate  interface{} `json:"user_state_update,omitempty"` // data for State.User of next requests
+	ApplicationState interface{} `json:"application_state,omitempty"` // data for State.Application of next requests
+	Analytics        *Analytics  `json:"analytics,omitempty"`         // events for skill AppMetrica dashboard
+
+	question string // pending yes/no question, see AskYesNo
 }
 
 // NewOutput creates new OutputData. Use i variable to provide InputDate to setup
@@ -428,6 +871,13 @@ func dangerous(i InputData) OutputData {
 	}
 }
 
-func isJSONNumberIsFloat(v json.RawMessage) bool {
-	return strings.Contains(string(v), ".")
+func fallback(i InputData) OutputData {
+	return OutputData{
+		Version: i.Version,
+		Session: i.Session,
+		Response: Response{
+			Text: "Извините, что-то пошло не так. Попробуйте, пожалуйста, еще раз.",
+			TTS:  "Извините, что-то пошло не так. Попробуйте, пожалуйста, еще раз.",
+		},
+	}
 }
@@ -19,12 +19,24 @@ func TestRequestType(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "\"ButtonPressed\"", string(s2))
 
-	s3 := []byte(`{"type1": "SimpleUtterance", "type2": "ButtonPressed"}`)
+	t3 := RequestTypePurchaseConfirmation
+	s3, err := json.Marshal(t3)
+	require.NoError(t, err)
+	require.Equal(t, "\"Purchase.Confirmation\"", string(s3))
+
+	t4 := RequestTypeShowPull
+	s4, err := json.Marshal(t4)
+	require.NoError(t, err)
+	require.Equal(t, "\"Show.Pull\"", string(s4))
+
+	s5 := []byte(`{"type1": "SimpleUtterance", "type2": "ButtonPressed", "type3": "Purchase.Confirmation", "type4": "Show.Pull"}`)
 	var m map[string]RequestType
-	err = json.Unmarshal(s3, &m)
+	err = json.Unmarshal(s5, &m)
 	require.NoError(t, err)
 	require.Equal(t, RequestTypeSimpleUtterance, m["type1"])
 	require.Equal(t, RequestTypeButtonPressed, m["type2"])
+	require.Equal(t, RequestTypePurchaseConfirmation, m["type3"])
+	require.Equal(t, RequestTypeShowPull, m["type4"])
 }
 
 func TestEntityType(t *testing.T) {
@@ -56,6 +68,39 @@ func TestEntityType(t *testing.T) {
 	require.Equal(t, EntityTypeFIO, m["e2"])
 	require.Equal(t, EntityTypeGeo, m["e3"])
 	require.Equal(t, EntityTypeNumber, m["e4"])
+
+	err = json.Unmarshal([]byte(`{"e5": "YANDEX.STRING", "e6": "CustomEntity"}`), &m)
+	require.NoError(t, err)
+	require.Equal(t, EntityTypeUnknown, m["e5"])
+	require.Equal(t, EntityTypeUnknown, m["e6"])
+	require.Error(t, json.Unmarshal([]byte(`{"e7": 1}`), &m))
+}
+
+func TestUnknownEntity(t *testing.T) {
+	var nlu RequestNLU
+	err := json.Unmarshal([]byte(`{
+		"tokens": ["закажи", "пиццу", "маргарита"],
+		"entities": [
+			{"tokens": {"start": 2, "end": 3}, "type": "YANDEX.STRING", "value": "маргарита"},
+			{"tokens": {"start": 0, "end": 1}, "type": "YANDEX.NUMBER", "value": 1}
+		]
+	}`), &nlu)
+	require.NoError(t, err)
+
+	e := nlu.Entities[0]
+	require.True(t, e.IsUnknown())
+	require.Equal(t, "YANDEX.STRING", e.TypeName)
+	_, err = e.FIOValue()
+	require.Error(t, err)
+	require.True(t, nlu.Entities[1].IsInt())
+	require.Equal(t, "YANDEX.NUMBER", nlu.Entities[1].TypeName)
+
+	data, err := json.Marshal(e)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"tokens":{"start":2,"end":3},"type":"YANDEX.STRING","value":"маргарита"}`, string(data))
+	data, err = json.Marshal(nlu.Entities[1])
+	require.NoError(t, err)
+	require.Equal(t, `{"tokens":{"start":0,"end":1},"type":"YANDEX.NUMBER","value":1}`, string(data))
 }
 
 func TestInputData(t *testing.T) {
@@ -256,6 +301,106 @@ func TestEntityValues(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestValueNumber(t *testing.T) {
+	entity := func(v string) RequestEntity {
+		var e RequestEntity
+		require.NoError(t, json.Unmarshal([]byte(`{"type":"YANDEX.NUMBER","value":`+v+`}`), &e))
+		return e
+	}
+
+	e := entity("1e3")
+	require.True(t, e.IsInt())
+	require.False(t, e.IsFloat())
+	iv, err := e.IntValue()
+	require.NoError(t, err)
+	require.Equal(t, 1000, iv)
+
+	e = entity("-42")
+	iv, err = e.IntValue()
+	require.NoError(t, err)
+	require.Equal(t, -42, iv)
+
+	e = entity("1.0")
+	require.True(t, e.IsFloat())
+	require.False(t, e.IsInt())
+	fv, err := e.FloatValue()
+	require.NoError(t, err)
+	require.Equal(t, 1.0, fv)
+	_, err = e.IntValue()
+	require.EqualError(t, err, "Cannot create integer for entity type YANDEX.NUMBER, float")
+
+	e = entity("16")
+	fv, err = e.FloatValue()
+	require.NoError(t, err)
+	require.Equal(t, 16.0, fv)
+
+	e = entity("2.5e-1")
+	require.True(t, e.IsFloat())
+	fv, err = e.FloatValue()
+	require.NoError(t, err)
+	require.Equal(t, 0.25, fv)
+
+	e = entity("9223372036854775807")
+	v, err := e.NumberValue()
+	require.NoError(t, err)
+	i64, err := v.Int64()
+	require.NoError(t, err)
+	require.Equal(t, int64(9223372036854775807), i64)
+
+	e = entity("9223372036854775808")
+	require.True(t, e.IsInt())
+	v, err = e.NumberValue()
+	require.NoError(t, err)
+	_, err = v.Int64()
+	require.EqualError(t, err, "Number 9223372036854775808 overflows int64")
+	f, err := v.Float64()
+	require.NoError(t, err)
+	require.Equal(t, 9223372036854775808.0, f)
+
+	e = RequestEntity{Type: EntityTypeGeo}
+	_, err = e.NumberValue()
+	require.EqualError(t, err, "Cannot create ValueNumber for entity type YANDEX.GEO")
+	require.False(t, e.IsInt())
+	require.False(t, e.IsFloat())
+}
+
+func TestEntitySpans(t *testing.T) {
+	var nlu RequestNLU
+	err := json.Unmarshal([]byte(`{
+		"tokens": ["забронируй", "столик", "с", "понедельника", "до", "пятницы", "на", "улице", "льва", "толстого"],
+		"entities": [
+			{"tokens": {"start": 5, "end": 6}, "type": "YANDEX.DATETIME", "value": {"day": 5}},
+			{"tokens": {"start": 3, "end": 4}, "type": "YANDEX.DATETIME", "value": {"day": 1}},
+			{"tokens": {"start": 7, "end": 10}, "type": "YANDEX.GEO", "value": {"street": "льва толстого"}},
+			{"tokens": {"start": 9, "end": 12}, "type": "YANDEX.FIO", "value": {"last_name": "толстой"}}
+		]
+	}`), &nlu)
+	require.NoError(t, err)
+
+	require.Equal(t, "улице льва толстого", nlu.EntityText(nlu.Entities[2]))
+	require.Equal(t, []string{"толстого"}, nlu.EntityTokens(nlu.Entities[3]))
+
+	dates := nlu.EntitiesOfType(EntityTypeDateTime)
+	require.Len(t, dates, 2)
+	require.Equal(t, "понедельника", nlu.EntityText(dates[0]))
+	require.Equal(t, "пятницы", nlu.EntityText(dates[1]))
+	require.Empty(t, nlu.EntitiesOfType(EntityTypeNumber))
+
+	require.Equal(t, "забронируй столик с до на улице льва толстого", nlu.CommandWithout(EntityTypeDateTime))
+	require.Equal(t, "забронируй столик с до на", nlu.CommandWithout(EntityTypeDateTime, EntityTypeGeo))
+
+	e, ok := nlu.FirstEntityAfter("до", EntityTypeDateTime)
+	require.True(t, ok)
+	require.Equal(t, "пятницы", nlu.EntityText(e))
+	e, ok = nlu.FirstEntityAfter("Забронируй столик", EntityTypeDateTime)
+	require.True(t, ok)
+	require.Equal(t, "понедельника", nlu.EntityText(e))
+	_, ok = nlu.FirstEntityAfter("до", EntityTypeNumber)
+	require.False(t, ok)
+	_, ok = nlu.FirstEntityAfter("после", EntityTypeDateTime)
+	require.False(t, ok)
+}
+
 func TestValueDateTimeAbsolute(t *testing.T) {
 	strAbs := []byte(`{
 		"year": 1982,
@@ -271,6 +416,7 @@ func TestValueDateTimeAbsolute(t *testing.T) {
 	err = json.Unmarshal(strAbs, &vl)
 	require.NoError(t, err)
 	require.False(t, vl.IsRelative())
+	require.True(t, vl.HasYear() && vl.HasMonth() && vl.HasDay() && vl.HasHour() && vl.HasMinute())
 	tv, err := vl.Time("Europe/Moscow")
 	require.NoError(t, err)
 	require.True(t, tv.Equal(timeAbs))
@@ -278,6 +424,19 @@ func TestValueDateTimeAbsolute(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestValueDateTimeFields(t *testing.T) {
+	var v ValueDateTime
+	require.NoError(t, json.Unmarshal([]byte(`{"day": 1, "day_is_relative": true, "hour": 0}`), &v))
+	require.True(t, v.HasDay())
+	require.True(t, v.HasHour())
+	require.False(t, v.HasMinute())
+	require.False(t, v.HasYear())
+
+	v = ValueDateTime{Hour: 15}
+	require.True(t, v.HasHour())
+	require.False(t, v.HasMinute())
+}
+
 func TestValueDateTimeRelative(t *testing.T) {
 	strRel := []byte(`{
 		"year": 3,
@@ -1,11 +1,23 @@
 package galice
 
 import (
+	"bytes"
+	"crypto/rsa"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"log"
+	"mime"
 	"net/http"
 	"os"
+	"reflect"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/temapavloff/galice/match"
 )
 
 // Logger is a signature for logging function used by Client
@@ -13,11 +25,33 @@ type Logger func(error)
 
 // Client represents Alice API client, allows to create HTTP handler function for Alice API incoming webhooks
 type Client struct {
-	autoPings            bool   // should Alice API healthcheks be handled automatically
-	autoDanderousContext bool   // should dangerous context be handled automatically
-	logger               Logger // logging function
+	autoPings            bool                     // should Alice API healthcheks be handled automatically
+	autoDanderousContext bool                     // should dangerous context be handled automatically
+	logger               Logger                   // logging function
+	skillIDs             map[string]struct{}      // allowed skill IDs, any skill ID is allowed if empty
+	strictHTTP           bool                     // should non POST and non JSON requests be rejected
+	maxBodySize          int64                    // maximum request body size in bytes, unlimited if zero
+	strictJSON           bool                     // should unknown request fields be reported to logger
+	dangerousHandler     AliceHandler             // custom handler for dangerous context requests
+	fallbackHandler      AliceHandler             // handler used when main handler panics
+	storage              Storage                  // session data storage
+	buttonsMatcher       *match.Matcher           // matcher for voice selection of buttons, disabled if nil
+	middlewares          []Middleware             // middlewares wrapping AliceHandler
+	purchaseHandler      AliceHandler             // handler for Purchase.Confirmation requests
+	purchaseKey          *rsa.PublicKey           // key for verification of Purchase.Confirmation requests
+	showHandler          AliceHandler             // handler for Show.Pull requests
+	helpHandler          AliceHandler             // handler for YANDEX.HELP and YANDEX.WHAT_CAN_YOU_DO intents
+	autoRepeat           bool                     // should the last response be replayed for YANDEX.REPEAT intent
+	yesNoHandler         YesNoHandler             // handler for answers to pending yes/no questions
+	confirmations        map[string]*Confirmation // registered confirmations keyed by Confirm key
+	confirmationsMu      sync.RWMutex             // guards confirmations
 }
 
+// DefaultMaxBodySize is a default limit of Alice request body size.
+// User input in Alice request is limited to 1024 characters and payloads to 4096 bytes,
+// so valid request never comes close to this value.
+const DefaultMaxBodySize = 64 * 1024
+
 // default logger for Client
 var defaultLogger = log.New(os.Stderr, "", 0)
 
@@ -30,17 +64,128 @@ func (c *Client) SetLogger(logger Logger) {
 	c.logger = logger
 }
 
+// SetSkillIDs sets the list of skill IDs allowed to be handled by current client.
+// Requests with any other Session.SkillID will be rejected with 403 status code.
+// If not called or called without arguments any skill ID is allowed.
+func (c *Client) SetSkillIDs(ids ...string) {
+	c.skillIDs = make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		c.skillIDs[id] = struct{}{}
+	}
+}
+
+// SetStrictHTTP tells client to reject requests with method other than POST
+// (405 status code) and with Content-Type other than application/json (415 status code).
+func (c *Client) SetStrictHTTP(strict bool) {
+	c.strictHTTP = strict
+}
+
+// SetMaxBodySize sets maximum size of request body in bytes.
+// Requests with larger body will be rejected with 413 status code.
+// If not called DefaultMaxBodySize is used. Zero value means no limit.
+func (c *Client) SetMaxBodySize(size int64) {
+	c.maxBodySize = size
+}
+
+// SetStrictJSON tells client to report request fields unknown to this SDK to logger.
+// Such requests are still handled as usual, so it is safe to use in production
+// to find out about Alice API protocol updates.
+func (c *Client) SetStrictJSON(strict bool) {
+	c.strictJSON = strict
+}
+
+// SetDangerousContextHandler sets handler for requests marked as dangerous by Alice API.
+// It is used only if autoDanderousContext flag passed to New is true.
+// If not called the default message in russian is sent in response to such requests.
+func (c *Client) SetDangerousContextHandler(fn AliceHandler) {
+	c.dangerousHandler = fn
+}
+
+// SetDangerousContextResponse sets response for requests marked as dangerous by Alice API.
+// It is a shortcut for SetDangerousContextHandler with handler which always returns r.
+func (c *Client) SetDangerousContextResponse(r Response) {
+	c.dangerousHandler = staticHandler(r)
+}
+
+// SetFallbackHandler sets handler called when AliceHandler panics or returns OutputData
+// which cannot be encoded. It allows to respond with some in-character error message and keep
+// session alive instead of responding with 500 status code, which Alice reports as
+// "skill is not responding". If not called the default apology message in russian is sent.
+// Passing nil restores responding with 500 status code.
+func (c *Client) SetFallbackHandler(fn AliceHandler) {
+	c.fallbackHandler = fn
+}
+
+// SetFallbackResponse sets response sent when AliceHandler panics or fails to encode.
+// It is a shortcut for SetFallbackHandler with handler which always returns r.
+func (c *Client) SetFallbackResponse(r Response) {
+	c.fallbackHandler = staticHandler(r)
+}
+
+// SetStorage sets storage for session data used by client features which keep data between
+// requests, e.g. SetVoiceButtons. If not called MemoryStorage with DefaultSessionTTL is used.
+func (c *Client) SetStorage(s Storage) {
+	c.storage = s
+}
+
+// Storage returns session data storage of current client, see SetStorage
+func (c *Client) Storage() Storage {
+	return c.storage
+}
+
+// SetVoiceButtons enables voice selection of buttons. Buttons of every response are saved
+// to the storage, and if the next request is SimpleUtterance matching title of one of them
+// it is passed to AliceHandler as if the button was pressed: with ButtonPressed type and button
+// payload, or with button title as command for buttons without payload. Button is selected only
+// if its score is not less than ButtonsThreshold, it is the only best one and utterance has no
+// negations ("не маргариту"). Use match.New() for default matching rules. Passing nil disables
+// voice selection.
+func (c *Client) SetVoiceButtons(m *match.Matcher) {
+	c.buttonsMatcher = m
+}
+
+// SetPurchaseHandler sets handler for Purchase.Confirmation requests. If key is not nil
+// requests are verified with VerifyPurchase before handling and rejected with 403 status code
+// if signature is invalid. If not called Purchase.Confirmation requests are passed to AliceHandler.
+func (c *Client) SetPurchaseHandler(fn AliceHandler, key *rsa.PublicKey) {
+	c.purchaseHandler = fn
+	c.purchaseKey = key
+}
+
+// SetShowHandler sets handler for Show.Pull requests sent by Alice to get skill content
+// for shows like "Alice's morning show". Use NewShowResponse to create response for such requests.
+// If not called Show.Pull requests are passed to AliceHandler.
+func (c *Client) SetShowHandler(fn AliceHandler) {
+	c.showHandler = fn
+}
+
+// Use adds middlewares wrapping AliceHandler passed to CreateHandler and handlers chosen
+// by client itself (dangerous context, purchases, shows, built-in intents, confirmations
+// and fallback handler, see InputData.IsFallback),
+// so it must be called before CreateHandler. The first middleware is the outermost one.
+func (c *Client) Use(mw ...Middleware) {
+	c.middlewares = append(c.middlewares, mw...)
+}
+
 // New creates new Alice API client. The autoPings flag tells client to automatically
 // respond to Alice API healthchecks. The autoDanderousContext tells client to
 // automatically handle requests marked as dangerous (suicide, hate speech, threats)
 // by Alice API.
 func New(autoPings bool, autoDanderousContext bool) *Client {
 	return &Client{
-		autoPings,
-		autoDanderousContext,
-		func(val error) {
+		autoPings:            autoPings,
+		autoDanderousContext: autoDanderousContext,
+		logger: func(val error) {
 			defaultLogger.Println(val)
 		},
+		maxBodySize: DefaultMaxBodySize,
+		dangerousHandler: func(i InputData) (OutputData, error) {
+			return dangerous(i), nil
+		},
+		fallbackHandler: func(i InputData) (OutputData, error) {
+			return fallback(i), nil
+		},
+		storage: NewMemoryStorage(DefaultSessionTTL),
 	}
 }
 
@@ -58,58 +203,341 @@ func (a *AliceHandlerError) Error() string {
 // AliceHandler is a signature of Alice request handler. It represents function
 // which accepts InputData (go struct, contains Alice API incoming data) and must
 // return OutputData (go struct, contains Alice API outcoming data) and optional error
-//Notice that error is used only for additional logging, so function mus return correct
+// Notice that error is used only for additional logging, so function mus return correct
 // OutputData even if something went wrong
 type AliceHandler func(InputData) (OutputData, error)
 
 // CreateHandler creates new http.Handler for Alice API incoming webhooks based on
-// provided AliceHandler
+// provided AliceHandler. Response is fully encoded before writing, so if AliceHandler
+// panics or returns unencodable OutputData the fallback response is sent with 200 status code.
 func (c *Client) CreateHandler(fn AliceHandler) http.Handler {
+	middlewares := append([]Middleware(nil), c.middlewares...)
+
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
 			if val := recover(); val != nil {
-				c.logger(fmt.Errorf("Unexpected error: %v", val))
+				c.logger(fmt.Errorf("Unexpected error: %v\n%s", val, debug.Stack()))
 				w.WriteHeader(http.StatusInternalServerError)
 			}
 		}()
 
 		w.Header().Set("Content-Type", "application/json")
-		err := c.handleRequest(w, r, fn)
+		body, err := c.handleRequest(w, r, fn, middlewares)
 		if err != nil {
 			c.logger(err)
 			w.WriteHeader(err.ResponseCode)
+			return
 		}
+		w.Write(body)
 	})
 }
 
-func (c *Client) handleRequest(w http.ResponseWriter, r *http.Request, fn AliceHandler) *AliceHandlerError {
+func staticHandler(r Response) AliceHandler {
+	return func(i InputData) (OutputData, error) {
+		return NewOutput(i, r), nil
+	}
+}
+
+// wrap applies middlewares to fn, the first middleware is the outermost one
+func wrap(fn AliceHandler, middlewares []Middleware) AliceHandler {
+	for n := len(middlewares) - 1; n >= 0; n-- {
+		fn = middlewares[n](fn)
+	}
+	return fn
+}
+
+func (c *Client) handleRequest(w http.ResponseWriter, r *http.Request, fn AliceHandler, middlewares []Middleware) ([]byte, *AliceHandlerError) {
+	i, aErr := c.readInput(w, r)
+	if aErr != nil {
+		return nil, aErr
+	}
+
+	if c.autoPings && i.Request.IsPing() {
+		body, err := encodeOutput(pong(i))
+		if err != nil {
+			return nil, &AliceHandlerError{fmt.Sprintf("Error marshaling response: %v", err), http.StatusInternalServerError}
+		}
+		return body, nil
+	}
+
+	if c.buttonsMatcher != nil {
+		if err := c.pressVoiceButton(&i); err != nil {
+			c.logger(err)
+		}
+	}
+
+	handler, aErr := c.route(i, fn)
+	if aErr != nil {
+		return nil, aErr
+	}
+
+	o, aErr := c.callHandler(wrap(handler, middlewares), i)
+
+	if aErr == nil {
+		body, err := encodeOutput(o)
+		if err == nil {
+			c.afterResponse(i, o)
+			return body, nil
+		}
+		aErr = &AliceHandlerError{
+			fmt.Sprintf("Error marshaling response: %v %v", err, requestContext(i)),
+			http.StatusInternalServerError,
+		}
+	}
+	if c.fallbackHandler == nil {
+		return nil, aErr
+	}
+
+	c.logger(aErr)
+	i.fallback = true
+	if o, aErr = c.callHandler(wrap(c.fallbackHandler, middlewares), i); aErr != nil {
+		return nil, aErr
+	}
+	body, err := encodeOutput(o)
+	if err != nil {
+		return nil, &AliceHandlerError{
+			fmt.Sprintf("Error marshaling fallback response: %v %v", err, requestContext(i)),
+			http.StatusInternalServerError,
+		}
+	}
+	c.afterResponse(i, o)
+	return body, nil
+}
+
+// afterResponse saves session data required for handling of the next request
+func (c *Client) afterResponse(i InputData, o OutputData) {
+	if c.buttonsMatcher != nil {
+		if err := c.saveButtons(i, o); err != nil {
+			c.logger(err)
+		}
+	}
+	if err := c.saveIntentsState(i, o); err != nil {
+		c.logger(err)
+	}
+}
+
+func (c *Client) readInput(w http.ResponseWriter, r *http.Request) (InputData, *AliceHandlerError) {
+	var i InputData
+
+	if aErr := c.checkHTTP(r); aErr != nil {
+		return i, aErr
+	}
+
 	if r.Body == nil {
-		return &AliceHandlerError{"Empty request body", http.StatusBadRequest}
+		return i, &AliceHandlerError{"Empty request body", http.StatusBadRequest}
 	}
 	defer r.Body.Close()
 
-	var err error
-	var i InputData
-	if err = json.NewDecoder(r.Body).Decode(&i); err != nil {
-		return &AliceHandlerError{fmt.Sprintf("Error while decoding Alice request: %v", err), http.StatusBadRequest}
+	body := r.Body
+	if c.maxBodySize > 0 {
+		body = http.MaxBytesReader(w, r.Body, c.maxBodySize)
 	}
 
-	var o OutputData
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		var mErr *http.MaxBytesError
+		if errors.As(err, &mErr) {
+			return i, &AliceHandlerError{fmt.Sprintf("Request body exceeds %v bytes", mErr.Limit), http.StatusRequestEntityTooLarge}
+		}
+		return i, &AliceHandlerError{fmt.Sprintf("Error while reading Alice request: %v", err), http.StatusBadRequest}
+	}
+
+	if err = json.Unmarshal(data, &i); err != nil {
+		return i, &AliceHandlerError{fmt.Sprintf("Error while decoding Alice request: %v", err), http.StatusBadRequest}
+	}
+
+	if c.strictJSON {
+		if fields := unknownFields(data, reflect.TypeOf(i), ""); len(fields) > 0 {
+			c.logger(fmt.Errorf("Unknown fields in Alice request: %v", strings.Join(fields, ", ")))
+		}
+	}
+
+	if !c.isSkillAllowed(i.Session.SkillID) {
+		return i, &AliceHandlerError{fmt.Sprintf("Skill ID is not allowed: %v", i.Session.SkillID), http.StatusForbidden}
+	}
+
+	return i, nil
+}
+
+// route chooses handler for request
+func (c *Client) route(i InputData, fn AliceHandler) (AliceHandler, *AliceHandlerError) {
 	switch {
-	case c.autoPings && i.Request.IsPing():
-		o = pong(i)
 	case c.autoDanderousContext && i.Request.IsDangerousContext():
-		o = dangerous(i)
-	default:
-		o, err = fn(i)
-		if err != nil {
-			c.logger(err)
+		return c.dangerousHandler, nil
+	case i.Request.IsPurchaseConfirmation() && c.purchaseHandler != nil:
+		if c.purchaseKey != nil {
+			if err := VerifyPurchase(c.purchaseKey, i.Request); err != nil {
+				return nil, &AliceHandlerError{fmt.Sprintf("%v %v", err, requestContext(i)), http.StatusForbidden}
+			}
 		}
+		return c.purchaseHandler, nil
+	case i.Request.IsShowPull() && c.showHandler != nil:
+		return c.showHandler, nil
+	}
+
+	h, err := c.routeIntent(i)
+	if err != nil {
+		c.logger(fmt.Errorf("Unable to handle built-in intent: %v %v", err, requestContext(i)))
+	}
+	if h != nil {
+		return h, nil
+	}
+	return fn, nil
+}
+
+// callHandler calls fn and converts its panic into AliceHandlerError
+func (c *Client) callHandler(fn AliceHandler, i InputData) (o OutputData, aErr *AliceHandlerError) {
+	defer func() {
+		if val := recover(); val != nil {
+			aErr = &AliceHandlerError{
+				fmt.Sprintf("Unexpected error: %v %v\n%s", val, requestContext(i), debug.Stack()),
+				http.StatusInternalServerError,
+			}
+		}
+	}()
+
+	o, err := fn(i)
+	if err != nil {
+		c.logger(err)
+	}
+	return o, nil
+}
+
+func encodeOutput(o OutputData) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(o); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// requestContext describes request for logging purposes
+func requestContext(i InputData) string {
+	return fmt.Sprintf("(session_id: %v, message_id: %v, command: %q)",
+		i.Session.SessionID, i.Session.MessageID, i.Request.Command)
+}
+
+func (c *Client) checkHTTP(r *http.Request) *AliceHandlerError {
+	if !c.strictHTTP {
+		return nil
+	}
+	if r.Method != http.MethodPost {
+		return &AliceHandlerError{fmt.Sprintf("Unsupported request method: %v", r.Method), http.StatusMethodNotAllowed}
+	}
+	ct, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || ct != "application/json" {
+		return &AliceHandlerError{fmt.Sprintf("Unsupported content type: %v", r.Header.Get("Content-Type")), http.StatusUnsupportedMediaType}
 	}
+	return nil
+}
 
-	if err = json.NewEncoder(w).Encode(o); err != nil {
-		return &AliceHandlerError{fmt.Sprintf("Error marshaling response: %v", err), http.StatusInternalServerError}
+func (c *Client) isSkillAllowed(id string) bool {
+	if len(c.skillIDs) == 0 {
+		return true
+	}
+	_, ok := c.skillIDs[id]
+	return ok
+}
+
+// unknownFields returns paths of all JSON object keys in data which have
+// no corresponding struct fields in t
+func unknownFields(data []byte, t reflect.Type, path string) []string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if reflect.PtrTo(t).Implements(reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()) {
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil
+		}
+		var res []string
+		for _, item := range items {
+			res = append(res, unknownFields(item, t.Elem(), path+"[]")...)
+		}
+		return res
+	case reflect.Map:
+		var items map[string]json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil
+		}
+		var res []string
+		for key, item := range items {
+			res = append(res, unknownFields(item, t.Elem(), path+"."+key)...)
+		}
+		sort.Strings(res)
+		return res
+	case reflect.Struct:
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(data, &obj); err != nil {
+			return nil
+		}
+		fields := make(map[string]reflect.Type, t.NumField())
+		jsonFields(t, fields)
+		keys := make([]string, 0, len(obj))
+		for key := range obj {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		var res []string
+		for _, key := range keys {
+			p := key
+			if path != "" {
+				p = path + "." + key
+			}
+			ft, ok := fields[strings.ToLower(key)]
+			if !ok {
+				res = append(res, p)
+				continue
+			}
+			res = append(res, unknownFields(obj[key], ft, p)...)
+		}
+		return res
 	}
 
 	return nil
 }
+
+// jsonFields collects types of struct fields by their lowercased JSON names the way
+// encoding/json does: unexported fields are skipped, fields of embedded structs are
+// promoted unless shadowed by fields of outer struct
+func jsonFields(t reflect.Type, fields map[string]reflect.Type) {
+	var embedded []reflect.Type
+	for n := 0; n < t.NumField(); n++ {
+		f := t.Field(n)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				embedded = append(embedded, ft)
+				continue
+			}
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[strings.ToLower(name)] = f.Type
+	}
+	for _, et := range embedded {
+		promoted := map[string]reflect.Type{}
+		jsonFields(et, promoted)
+		for name, ft := range promoted {
+			if _, ok := fields[name]; !ok {
+				fields[name] = ft
+			}
+		}
+	}
+}
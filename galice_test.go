@@ -5,6 +5,8 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -126,8 +128,37 @@ func TestHandlingUnexpectedError(t *testing.T) {
 	require.NoError(t, err)
 	rr := httptest.NewRecorder()
 	h.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Contains(t, rr.Body.String(), "Извините, что-то пошло не так")
+	require.True(t, strings.HasPrefix(errStr, "Unexpected error: test (session_id: , message_id: 0, command: \"\")\n"))
+
+	cli.SetFallbackHandler(nil)
+	req, err = http.NewRequest("POST", "/skill", bytes.NewReader([]byte("{}")))
+	require.NoError(t, err)
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
 	require.Equal(t, http.StatusInternalServerError, rr.Code)
-	require.Equal(t, "Unexpected error: test", errStr)
+	require.Empty(t, rr.Body.String())
+}
+
+func TestHandlingEncodingError(t *testing.T) {
+	cli := New(true, true)
+	errStr := ""
+	cli.SetLogger(func(err error) {
+		errStr = err.Error()
+	})
+	h := cli.CreateHandler(func(i InputData) (OutputData, error) {
+		r := NewResponse("test", "", false)
+		r.AddButton("test", false, "", make(chan int))
+		return NewOutput(i, r), nil
+	})
+	req, err := http.NewRequest("POST", "/skill", bytes.NewReader([]byte(`{"request": {"command": "test"}}`)))
+	require.NoError(t, err)
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Contains(t, rr.Body.String(), "Извините, что-то пошло не так")
+	require.Equal(t, "Error marshaling response: json: unsupported type: chan int (session_id: , message_id: 0, command: \"test\")", errStr)
 }
 
 func TestGeneralResponse(t *testing.T) {
@@ -174,3 +205,170 @@ func TestGeneralResponse(t *testing.T) {
 	require.Equal(t, http.StatusOK, rr.Code)
 	require.Equal(t, resp, rr.Body.String())
 }
+
+func TestSkillIDs(t *testing.T) {
+	body := `{"request": {"command": "test", "original_utterance": "test", "type": "SimpleUtterance"}, "session": {"skill_id": "skill-1"}, "version": "1.0"}`
+	cli := New(true, true)
+	cli.SetLogger(func(err error) {})
+	h := cli.CreateHandler(func(i InputData) (OutputData, error) {
+		return NewOutput(i, NewResponse("test", "", false)), nil
+	})
+
+	cli.SetSkillIDs("skill-1", "skill-2")
+	req, err := http.NewRequest("POST", "/skill", bytes.NewReader([]byte(body)))
+	require.NoError(t, err)
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	cli.SetSkillIDs("skill-2")
+	req, err = http.NewRequest("POST", "/skill", bytes.NewReader([]byte(body)))
+	require.NoError(t, err)
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusForbidden, rr.Code)
+}
+
+func TestStrictHTTP(t *testing.T) {
+	cli := New(true, true)
+	cli.SetLogger(func(err error) {})
+	cli.SetStrictHTTP(true)
+	h := cli.CreateHandler(func(i InputData) (OutputData, error) {
+		return NewOutput(i, NewResponse("test", "", false)), nil
+	})
+
+	req, err := http.NewRequest("GET", "/skill", nil)
+	require.NoError(t, err)
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+
+	req, err = http.NewRequest("POST", "/skill", bytes.NewReader([]byte("{}")))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "text/plain")
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusUnsupportedMediaType, rr.Code)
+
+	req, err = http.NewRequest("POST", "/skill", bytes.NewReader([]byte("{}")))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestMaxBodySize(t *testing.T) {
+	cli := New(true, true)
+	errStr := ""
+	cli.SetLogger(func(err error) {
+		errStr = err.Error()
+	})
+	cli.SetMaxBodySize(16)
+	h := cli.CreateHandler(func(i InputData) (OutputData, error) {
+		return NewOutput(i, NewResponse("test", "", false)), nil
+	})
+
+	req, err := http.NewRequest("POST", "/skill", bytes.NewReader([]byte(`{"version": "1.0", "meta": {"locale": "ru-RU"}}`)))
+	require.NoError(t, err)
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+	require.Equal(t, "Request body exceeds 16 bytes", errStr)
+}
+
+func TestStrictJSON(t *testing.T) {
+	body := `{
+	"meta": {"locale": "ru-RU", "timezone": "Europe/Moscow", "client_id": "test", "interfaces": {"screen": {}}},
+	"request": {
+		"command": "test",
+		"original_utterance": "test",
+		"type": "SimpleUtterance",
+		"nlu": {"tokens": ["test"], "entities": [], "intents": {}}
+	},
+	"session": {"new": true, "message_id": 1, "session_id": "1", "skill_id": "1", "user_id": "1", "application": {"application_id": "1"}},
+	"state": {"session": {}, "audio_player": {}},
+	"version": "1.0"
+}`
+	cli := New(true, true)
+	var errs []string
+	cli.SetLogger(func(err error) {
+		errs = append(errs, err.Error())
+	})
+	h := cli.CreateHandler(func(i InputData) (OutputData, error) {
+		return NewOutput(i, NewResponse("test", "", false)), nil
+	})
+
+	req, err := http.NewRequest("POST", "/skill", bytes.NewReader([]byte(body)))
+	require.NoError(t, err)
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Empty(t, errs)
+
+	cli.SetStrictJSON(true)
+	req, err = http.NewRequest("POST", "/skill", bytes.NewReader([]byte(body)))
+	require.NoError(t, err)
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, []string{"Unknown fields in Alice request: session.application, state.audio_player"}, errs)
+}
+
+func TestUnknownFields(t *testing.T) {
+	type base struct {
+		ID     string `json:"id"`
+		secret string
+	}
+	type item struct {
+		base
+		*Meta
+		Name   string `json:"name"`
+		hidden bool
+	}
+	data := []byte(`{"id": "1", "name": "a", "locale": "ru-RU", "secret": "s", "hidden": true, "base": {}}`)
+	require.Equal(t, []string{"base", "hidden", "secret"}, unknownFields(data, reflect.TypeOf(item{}), ""))
+
+	data = []byte(`{"request": {"command": "test"}, "fallback": true, "confirmationArgs": {}}`)
+	require.Equal(t, []string{"confirmationArgs", "fallback"}, unknownFields(data, reflect.TypeOf(InputData{}), ""))
+}
+
+func TestCustomDangerousContext(t *testing.T) {
+	body := `{"request": {"command": "test", "original_utterance": "test", "type": "SimpleUtterance", "markup": {"dangerous_context": true}}, "session": {"message_id": 1}, "version": "1.0"}`
+	resp := `{"version":"1.0","session":{"new":false,"message_id":1,"session_id":"","skill_id":"","user_id":""},"response":{"text":"Давайте о другом","tts":"Давайте о другом","end_session":false}}
+`
+	cli := New(true, true)
+	cli.SetDangerousContextResponse(NewResponse("Давайте о другом", "", false))
+	h := cli.CreateHandler(func(i InputData) (OutputData, error) {
+		return OutputData{}, nil
+	})
+
+	req, err := http.NewRequest("POST", "/skill", bytes.NewReader([]byte(body)))
+	require.NoError(t, err)
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, resp, rr.Body.String())
+}
+
+func TestFallbackResponse(t *testing.T) {
+	resp := `{"version":"1.0","session":{"new":false,"message_id":1,"session_id":"","skill_id":"","user_id":""},"response":{"text":"Ой, что-то пошло не так","tts":"Ой, что-то пошло не так","end_session":false}}
+`
+	cli := New(true, true)
+	errStr := ""
+	cli.SetLogger(func(err error) {
+		errStr = err.Error()
+	})
+	cli.SetFallbackResponse(NewResponse("Ой, что-то пошло не так", "", false))
+	h := cli.CreateHandler(func(i InputData) (OutputData, error) {
+		panic(errors.New("test"))
+	})
+
+	req, err := http.NewRequest("POST", "/skill", bytes.NewReader([]byte(`{"session": {"message_id": 1}, "version": "1.0"}`)))
+	require.NoError(t, err)
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, resp, rr.Body.String())
+	require.True(t, strings.HasPrefix(errStr, "Unexpected error: test (session_id: , message_id: 1, command: \"\")\n"))
+}
@@ -0,0 +1,337 @@
+// Package i18n provides message catalogs for localizing Alice skills based on Meta.Locale
+// of incoming request. Every message has text and optional TTS variant and may have
+// plural forms, so one message key is enough to build complete galice.Response.
+//
+// Catalogs are loaded from JSON files like this:
+//
+//	{
+//		"hello": "Привет!",
+//		"bye": {"text": "Пока 👋", "tts": "Пока"},
+//		"apples": {"one": "{n} яблоко", "few": "{n} яблока", "many": "{n} яблок"}
+//	}
+//
+// The same messages can be loaded from YAML files, and catalogs produced by gotext tool
+// (golang.org/x/text/cmd/gotext) are supported too. Messages from other sources can be
+// added with Catalog.Add.
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/temapavloff/galice"
+)
+
+// Phrase is a pair of text and text to speach values
+type Phrase struct {
+	Text string `json:"text"`
+	TTS  string `json:"tts"` // if empty Text is used
+}
+
+// Message is a localized message. Plural messages have Forms set
+// and use Phrase only if there is no suitable form.
+type Message struct {
+	Phrase
+	Forms map[PluralForm]Phrase
+}
+
+// UnmarshalJSON decodes message from string, object with text and tts keys
+// or object with plural forms keys (one, few, many, other)
+func (m *Message) UnmarshalJSON(input []byte) error {
+	var str string
+	if err := json.Unmarshal(input, &str); err == nil {
+		m.Text = str
+		return nil
+	}
+
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(input, &obj); err != nil {
+		return fmt.Errorf("Unsupported message value: %v", string(input))
+	}
+	for key, val := range obj {
+		switch key {
+		case "text":
+			if err := json.Unmarshal(val, &m.Text); err != nil {
+				return err
+			}
+		case "tts":
+			if err := json.Unmarshal(val, &m.TTS); err != nil {
+				return err
+			}
+		default:
+			var form Message
+			if err := json.Unmarshal(val, &form); err != nil {
+				return err
+			}
+			if m.Forms == nil {
+				m.Forms = map[PluralForm]Phrase{}
+			}
+			m.Forms[PluralForm(key)] = form.Phrase
+		}
+	}
+
+	return nil
+}
+
+// Args are values for message placeholders, placeholder {name} is replaced with Args["name"]
+type Args map[string]interface{}
+
+// Catalog is a set of messages in different locales
+type Catalog struct {
+	mu            sync.RWMutex
+	defaultLocale string
+	messages      map[string]map[string]Message // messages by locale and key
+	fallbacks     map[string][]string           // additional locales to search messages in
+}
+
+// NewCatalog creates new empty catalog. Messages of defaultLocale are used when
+// there is no message in requested locale.
+func NewCatalog(defaultLocale string) *Catalog {
+	return &Catalog{
+		defaultLocale: normalize(defaultLocale),
+		messages:      map[string]map[string]Message{},
+		fallbacks:     map[string][]string{},
+	}
+}
+
+// Add adds message to catalog
+func (c *Catalog) Add(locale, key string, m Message) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	locale = normalize(locale)
+	if c.messages[locale] == nil {
+		c.messages[locale] = map[string]Message{}
+	}
+	c.messages[locale][key] = m
+}
+
+// LoadJSON adds messages of locale from JSON object with message keys as object keys
+func (c *Catalog) LoadJSON(locale string, r io.Reader) error {
+	var messages map[string]Message
+	if err := json.NewDecoder(r).Decode(&messages); err != nil {
+		return fmt.Errorf("Unable to decode %v messages: %v", locale, err)
+	}
+	for key, m := range messages {
+		c.Add(locale, key, m)
+	}
+	return nil
+}
+
+// LoadFiles adds messages from files matching pattern (e.g. "locales/*.json"). Format is chosen
+// by file extension: ".json" for JSON, ".yaml" and ".yml" for YAML and ".gotext.json" for gotext
+// catalogs. Locale is taken from file name: messages from "locales/ru-RU.json" are added to ru-RU
+// locale, locale of gotext catalogs is taken from their content.
+func (c *Catalog) LoadFiles(pattern string) error {
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		if err := c.loadFile(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Catalog) loadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	name := filepath.Base(path)
+	locale := strings.TrimSuffix(name, filepath.Ext(name))
+	switch ext := strings.ToLower(filepath.Ext(name)); {
+	case strings.HasSuffix(strings.ToLower(name), ".gotext.json"):
+		return c.LoadGotext(f)
+	case ext == ".yaml" || ext == ".yml":
+		return c.LoadYAML(locale, f)
+	}
+	return c.LoadJSON(locale, f)
+}
+
+// SetFallback sets locales to search messages in when they are not found in locale,
+// e.g. SetFallback("uz", "ru") makes uzbek users get russian messages instead of
+// messages of default locale. Fallbacks are searched before default locale.
+func (c *Catalog) SetFallback(locale string, fallbacks ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	normalized := make([]string, len(fallbacks))
+	for n, f := range fallbacks {
+		normalized[n] = normalize(f)
+	}
+	c.fallbacks[normalize(locale)] = normalized
+}
+
+// Localizer creates Localizer for locale. Messages are searched in exact locale (ru-RU),
+// its language (ru), locales set by SetFallback and default locale.
+func (c *Catalog) Localizer(locale string) *Localizer {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var chain []string
+	seen := map[string]bool{}
+	add := func(tags []string) {
+		for _, tag := range tags {
+			if !seen[tag] {
+				seen[tag] = true
+				chain = append(chain, tag)
+			}
+		}
+	}
+
+	for _, tag := range candidates(locale) {
+		add([]string{tag})
+		for _, f := range c.fallbacks[tag] {
+			add(candidates(f))
+		}
+	}
+	add(candidates(c.defaultLocale))
+
+	return &Localizer{c, chain}
+}
+
+// ForInput creates Localizer for locale of Alice request
+func (c *Catalog) ForInput(i galice.InputData) *Localizer {
+	return c.Localizer(i.Meta.Locale)
+}
+
+// Localizer provides messages of the catalog for some locale
+type Localizer struct {
+	catalog *Catalog
+	locales []string // locales to search messages in, in order of preference
+}
+
+// Locale returns the most preferred locale which has any messages in catalog
+func (l *Localizer) Locale() string {
+	l.catalog.mu.RLock()
+	defer l.catalog.mu.RUnlock()
+
+	for _, locale := range l.locales {
+		if len(l.catalog.messages[locale]) > 0 {
+			return locale
+		}
+	}
+	return l.catalog.defaultLocale
+}
+
+// Has checks if message exists in any locale of current Localizer
+func (l *Localizer) Has(key string) bool {
+	_, _, ok := l.find(key)
+	return ok
+}
+
+// Phrase returns message with placeholders replaced with args.
+// If message does not exist key is returned as text.
+func (l *Localizer) Phrase(key string, args Args) Phrase {
+	m, _, ok := l.find(key)
+	if !ok {
+		return Phrase{key, key}
+	}
+	return m.Phrase.format(args)
+}
+
+// PluralPhrase returns plural form of message suitable for n with placeholders replaced
+// with args. Placeholder {n} is replaced with n unless args have other value for it.
+// If message does not exist key is returned as text.
+func (l *Localizer) PluralPhrase(key string, n int64, args Args) Phrase {
+	m, locale, ok := l.find(key)
+	if !ok {
+		return Phrase{key, key}
+	}
+
+	withN := Args{"n": n}
+	for k, v := range args {
+		withN[k] = v
+	}
+
+	p, ok := m.Forms[Plural(locale, n)]
+	if !ok {
+		p, ok = m.Forms[PluralOther]
+	}
+	if !ok {
+		p = m.Phrase
+	}
+	return p.format(withN)
+}
+
+// Text returns text of message, see Phrase
+func (l *Localizer) Text(key string, args Args) string {
+	return l.Phrase(key, args).Text
+}
+
+// PluralText returns text of plural message, see PluralPhrase
+func (l *Localizer) PluralText(key string, n int64, args Args) string {
+	return l.PluralPhrase(key, n, args).Text
+}
+
+// Response creates galice.Response with text and TTS of message, see Phrase
+func (l *Localizer) Response(key string, args Args, endSession bool) galice.Response {
+	p := l.Phrase(key, args)
+	return galice.NewResponse(p.Text, p.TTS, endSession)
+}
+
+// PluralResponse creates galice.Response with text and TTS of plural message, see PluralPhrase
+func (l *Localizer) PluralResponse(key string, n int64, args Args, endSession bool) galice.Response {
+	p := l.PluralPhrase(key, n, args)
+	return galice.NewResponse(p.Text, p.TTS, endSession)
+}
+
+func (l *Localizer) find(key string) (Message, string, bool) {
+	l.catalog.mu.RLock()
+	defer l.catalog.mu.RUnlock()
+
+	for _, locale := range l.locales {
+		if m, ok := l.catalog.messages[locale][key]; ok {
+			return m, locale, true
+		}
+	}
+	return Message{}, "", false
+}
+
+func (p Phrase) format(args Args) Phrase {
+	if p.TTS == "" {
+		p.TTS = p.Text
+	}
+	if len(args) == 0 {
+		return p
+	}
+
+	pairs := make([]string, 0, len(args)*2)
+	for k, v := range args {
+		pairs = append(pairs, "{"+k+"}", fmt.Sprint(v))
+	}
+	r := strings.NewReplacer(pairs...)
+	return Phrase{r.Replace(p.Text), r.Replace(p.TTS)}
+}
+
+// normalize converts locale to lowercase form with dash separator: ru_RU -> ru-ru
+func normalize(locale string) string {
+	return strings.ToLower(strings.Replace(strings.TrimSpace(locale), "_", "-", -1))
+}
+
+// candidates returns locale and its more general variants: ru-RU -> [ru-ru, ru]
+func candidates(locale string) []string {
+	locale = normalize(locale)
+	if locale == "" {
+		return nil
+	}
+	res := []string{locale}
+	for {
+		idx := strings.LastIndex(locale, "-")
+		if idx < 0 {
+			return res
+		}
+		locale = locale[:idx]
+		res = append(res, locale)
+	}
+}
@@ -0,0 +1,100 @@
+package i18n
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/temapavloff/galice"
+)
+
+const ruMessages = `{
+	"hello": "Привет, {name}!",
+	"bye": {"text": "Пока 👋", "tts": "Пока"},
+	"apples": {"one": "{n} яблоко", "few": "{n} яблока", "many": "{n} яблок"},
+	"only_ru": "Только по-русски"
+}`
+
+const kkMessages = `{
+	"hello": "Сәлем, {name}!",
+	"apples": {"one": "{n} алма", "other": "{n} алма"}
+}`
+
+func newTestCatalog(t *testing.T) *Catalog {
+	c := NewCatalog("ru")
+	require.NoError(t, c.LoadJSON("ru", strings.NewReader(ruMessages)))
+	require.NoError(t, c.LoadJSON("kk", strings.NewReader(kkMessages)))
+	c.Add("en", "hello", Message{Phrase: Phrase{Text: "Hello, {name}!"}})
+	return c
+}
+
+func TestLocalizerPhrase(t *testing.T) {
+	c := newTestCatalog(t)
+	l := c.Localizer("ru-RU")
+
+	require.Equal(t, "ru", l.Locale())
+	require.Equal(t, Phrase{"Привет, Лев!", "Привет, Лев!"}, l.Phrase("hello", Args{"name": "Лев"}))
+	require.Equal(t, Phrase{"Пока 👋", "Пока"}, l.Phrase("bye", nil))
+	require.Equal(t, "unknown", l.Text("unknown", nil))
+	require.False(t, l.Has("unknown"))
+}
+
+func TestLocalizerPlural(t *testing.T) {
+	c := newTestCatalog(t)
+	ru := c.Localizer("ru-RU")
+	require.Equal(t, "1 яблоко", ru.PluralText("apples", 1, nil))
+	require.Equal(t, "2 яблока", ru.PluralText("apples", 2, nil))
+	require.Equal(t, "5 яблок", ru.PluralText("apples", 5, nil))
+	require.Equal(t, "много яблок", ru.PluralText("apples", 5, Args{"n": "много"}))
+
+	kk := c.Localizer("kk-KZ")
+	require.Equal(t, "kk", kk.Locale())
+	require.Equal(t, "2 алма", kk.PluralText("apples", 2, nil))
+}
+
+func TestLocalizerFallbacks(t *testing.T) {
+	c := newTestCatalog(t)
+
+	kk := c.Localizer("kk-KZ")
+	require.Equal(t, "Сәлем, Абай!", kk.Text("hello", Args{"name": "Абай"}))
+	require.Equal(t, "Только по-русски", kk.Text("only_ru", nil))
+
+	uz := c.Localizer("uz-UZ")
+	require.Equal(t, "ru", uz.Locale())
+	require.Equal(t, "Привет, Алишер!", uz.Text("hello", Args{"name": "Алишер"}))
+
+	c.SetFallback("uz", "kk")
+	uz = c.Localizer("uz-UZ")
+	require.Equal(t, "kk", uz.Locale())
+	require.Equal(t, "Сәлем, Алишер!", uz.Text("hello", Args{"name": "Алишер"}))
+
+	en := c.ForInput(galice.InputData{Meta: galice.Meta{Locale: "en-US"}})
+	require.Equal(t, "Hello, John!", en.Text("hello", Args{"name": "John"}))
+}
+
+func TestLocalizerResponse(t *testing.T) {
+	c := newTestCatalog(t)
+	l := c.Localizer("ru-RU")
+
+	require.Equal(t, galice.NewResponse("Пока 👋", "Пока", true), l.Response("bye", nil, true))
+	require.Equal(t, galice.NewResponse("3 яблока", "", false), l.PluralResponse("apples", 3, nil, false))
+}
+
+func TestLoadFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "i18n")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "ru-RU.json"), []byte(ruMessages), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "kk.json"), []byte(kkMessages), 0644))
+
+	c := NewCatalog("ru-RU")
+	require.NoError(t, c.LoadFiles(filepath.Join(dir, "*.json")))
+	require.Equal(t, "Привет, Лев!", c.Localizer("ru").Text("hello", Args{"name": "Лев"}))
+	require.Equal(t, "Сәлем, Абай!", c.Localizer("kk").Text("hello", Args{"name": "Абай"}))
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "en.json"), []byte("[]"), 0644))
+	require.Error(t, c.LoadFiles(filepath.Join(dir, "*.json")))
+}
@@ -0,0 +1,119 @@
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LoadYAML adds messages of locale from YAML mapping with message keys as mapping keys.
+// Messages have the same structure as in LoadJSON:
+//
+//	hello: Привет!
+//	bye: {text: Пока 👋, tts: Пока}
+//	apples: {one: "{n} яблоко", few: "{n} яблока", many: "{n} яблок"}
+func (c *Catalog) LoadYAML(locale string, r io.Reader) error {
+	var messages map[string]interface{}
+	if err := yaml.NewDecoder(r).Decode(&messages); err != nil && err != io.EOF {
+		return fmt.Errorf("Unable to decode %v messages: %v", locale, err)
+	}
+	// messages are converted to JSON to share decoding rules with LoadJSON
+	data, err := json.Marshal(messages)
+	if err != nil {
+		return fmt.Errorf("Unable to decode %v messages: %v", locale, err)
+	}
+	return c.LoadJSON(locale, strings.NewReader(string(data)))
+}
+
+// gotextCatalog is a catalog produced by gotext tool (golang.org/x/text/cmd/gotext)
+type gotextCatalog struct {
+	Language string `json:"language"`
+	Messages []struct {
+		ID          json.RawMessage `json:"id"` // string or list of strings
+		Message     string          `json:"message"`
+		Translation json.RawMessage `json:"translation"` // string or plural select
+	} `json:"messages"`
+}
+
+// gotextSelect is a plural translation of gotext catalog
+type gotextSelect struct {
+	Select struct {
+		Feature string                     `json:"feature"`
+		Cases   map[string]json.RawMessage `json:"cases"` // string or {"msg": string}
+	} `json:"select"`
+}
+
+// LoadGotext adds messages from gotext catalog (messages.gotext.json or out.gotext.json),
+// locale is taken from its language field. Messages with plural select get forms of its
+// cases (one, few, many, other), exact value cases like "=0" are ignored. Placeholders
+// of translations are kept as is, so {Count} is replaced with Args["Count"].
+func (c *Catalog) LoadGotext(r io.Reader) error {
+	var cat gotextCatalog
+	if err := json.NewDecoder(r).Decode(&cat); err != nil {
+		return fmt.Errorf("Unable to decode gotext messages: %v", err)
+	}
+	if cat.Language == "" {
+		return fmt.Errorf("Unable to decode gotext messages: language is not set")
+	}
+
+	for _, gm := range cat.Messages {
+		key, err := gotextString(gm.ID)
+		if err == nil && key == "" {
+			err = fmt.Errorf("ID is empty")
+		}
+		if err != nil {
+			return fmt.Errorf("Unable to decode %v message ID: %v", cat.Language, err)
+		}
+
+		var m Message
+		if text, err := gotextString(gm.Translation); err == nil {
+			m.Text = text
+		} else {
+			var sel gotextSelect
+			if err := json.Unmarshal(gm.Translation, &sel); err != nil || sel.Select.Feature != "plural" {
+				return fmt.Errorf("Unsupported translation of %v message %q: %v", cat.Language, key, string(gm.Translation))
+			}
+			m.Forms = map[PluralForm]Phrase{}
+			for form, val := range sel.Select.Cases {
+				if strings.HasPrefix(form, "=") {
+					continue
+				}
+				text, err := gotextString(val)
+				if err != nil {
+					return fmt.Errorf("Unsupported translation of %v message %q: %v", cat.Language, key, string(val))
+				}
+				m.Forms[PluralForm(form)] = Phrase{Text: text}
+			}
+		}
+		if m.Text == "" && len(m.Forms) == 0 {
+			m.Text = gm.Message
+		}
+		c.Add(cat.Language, key, m)
+	}
+	return nil
+}
+
+// gotextString decodes string, the first string of list or {"msg": string}
+func gotextString(data json.RawMessage) (string, error) {
+	if len(data) == 0 {
+		return "", nil
+	}
+	var str string
+	if err := json.Unmarshal(data, &str); err == nil {
+		return str, nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err == nil && len(list) > 0 {
+		return list[0], nil
+	}
+	var msg struct {
+		Msg *string `json:"msg"`
+	}
+	if err := json.Unmarshal(data, &msg); err == nil && msg.Msg != nil {
+		return *msg.Msg, nil
+	}
+	return "", fmt.Errorf("unsupported value %v", string(data))
+}
@@ -0,0 +1,78 @@
+package i18n
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const ruYAML = `
+hello: Привет, {name}!
+bye: {text: Пока 👋, tts: Пока}
+apples:
+  one: "{n} яблоко"
+  few: "{n} яблока"
+  many: "{n} яблок"
+`
+
+const uzGotext = `{
+	"language": "uz",
+	"messages": [
+		{"id": "hello", "message": "Hello, {name}!", "translation": "Salom, {name}!"},
+		{"id": ["bye", "Goodbye"], "message": "Goodbye", "translation": ""},
+		{
+			"id": "apples",
+			"message": "{n} apples",
+			"translation": {"select": {"feature": "plural", "arg": "n", "cases": {
+				"=0": {"msg": "Olma yo'q"},
+				"one": {"msg": "{n} olma"},
+				"other": "{n} ta olma"
+			}}}
+		}
+	]
+}`
+
+func TestLoadYAML(t *testing.T) {
+	c := NewCatalog("ru")
+	require.NoError(t, c.LoadYAML("ru", strings.NewReader(ruYAML)))
+	l := c.Localizer("ru-RU")
+	require.Equal(t, "Привет, Лев!", l.Text("hello", Args{"name": "Лев"}))
+	require.Equal(t, Phrase{"Пока 👋", "Пока"}, l.Phrase("bye", nil))
+	require.Equal(t, "21 яблоко", l.PluralText("apples", 21, nil))
+	require.Equal(t, "5 яблок", l.PluralText("apples", 5, nil))
+
+	require.NoError(t, c.LoadYAML("kk", strings.NewReader("")))
+	require.Error(t, c.LoadYAML("kk", strings.NewReader("- hello")))
+}
+
+func TestLoadGotext(t *testing.T) {
+	c := NewCatalog("ru")
+	require.NoError(t, c.LoadGotext(strings.NewReader(uzGotext)))
+	l := c.Localizer("uz")
+	require.Equal(t, "Salom, Abay!", l.Text("hello", Args{"name": "Abay"}))
+	require.Equal(t, "Goodbye", l.Text("bye", nil))
+	require.Equal(t, "1 olma", l.PluralText("apples", 1, nil))
+	require.Equal(t, "0 ta olma", l.PluralText("apples", 0, nil))
+
+	require.Error(t, c.LoadGotext(strings.NewReader(`{"messages": []}`)))
+	require.Error(t, c.LoadGotext(strings.NewReader(`{"language": "uz", "messages": [{"id": "a", "translation": {"select": {"feature": "gender"}}}]}`)))
+}
+
+func TestLoadFilesFormats(t *testing.T) {
+	dir, err := ioutil.TempDir("", "i18n")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "ru.yaml"), []byte(ruYAML), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "kk.yml"), []byte("hello: Сәлем, {name}!"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "messages.gotext.json"), []byte(uzGotext), 0644))
+
+	c := NewCatalog("ru")
+	require.NoError(t, c.LoadFiles(filepath.Join(dir, "*")))
+	require.Equal(t, "Привет, Лев!", c.Localizer("ru").Text("hello", Args{"name": "Лев"}))
+	require.Equal(t, "Сәлем, Абай!", c.Localizer("kk").Text("hello", Args{"name": "Абай"}))
+	require.Equal(t, "Salom, Abay!", c.Localizer("uz").Text("hello", Args{"name": "Abay"}))
+}
@@ -0,0 +1,69 @@
+package i18n
+
+import (
+	"sync"
+
+	"github.com/temapavloff/galice/internal/plural"
+)
+
+// PluralForm is a CLDR plural category of a number
+type PluralForm string
+
+const (
+	// PluralOne is a form used for 1, 21, 31... in russian ("1 яблоко")
+	PluralOne = PluralForm("one")
+	// PluralFew is a form used for 2-4, 22-24... in russian ("2 яблока")
+	PluralFew = PluralForm("few")
+	// PluralMany is a form used for 0, 5-20, 25-30... in russian ("5 яблок")
+	PluralMany = PluralForm("many")
+	// PluralOther is a general form, used when there is no specific form for a number
+	PluralOther = PluralForm("other")
+)
+
+// PluralRule is a signature of function which returns plural form of n for some language
+type PluralRule func(n int64) PluralForm
+
+var (
+	rulesMu sync.RWMutex
+	rules   = map[string]PluralRule{
+		"ru": eastSlavicRule,
+		"uk": eastSlavicRule,
+		"be": eastSlavicRule,
+		"kk": oneOtherRule,
+		"uz": oneOtherRule,
+		"en": oneOtherRule,
+		"tr": oneOtherRule,
+	}
+)
+
+// RegisterPluralRule sets plural rule for language, e.g. "ru" or "kk".
+// Rules for russian, ukrainian, belarusian, kazakh, uzbek, turkish and english are registered by default.
+func RegisterPluralRule(lang string, rule PluralRule) {
+	rulesMu.Lock()
+	defer rulesMu.Unlock()
+	rules[normalize(lang)] = rule
+}
+
+// Plural returns plural form of n for locale (e.g. "ru-RU").
+// PluralOther is returned for locales without registered rule.
+func Plural(locale string, n int64) PluralForm {
+	rulesMu.RLock()
+	defer rulesMu.RUnlock()
+	for _, tag := range candidates(locale) {
+		if rule, ok := rules[tag]; ok {
+			return rule(n)
+		}
+	}
+	return PluralOther
+}
+
+func eastSlavicRule(n int64) PluralForm {
+	return [...]PluralForm{PluralOne, PluralFew, PluralMany}[plural.Russian(n)]
+}
+
+func oneOtherRule(n int64) PluralForm {
+	if n == 1 || n == -1 {
+		return PluralOne
+	}
+	return PluralOther
+}
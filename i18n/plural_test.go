@@ -0,0 +1,43 @@
+package i18n
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPluralRussian(t *testing.T) {
+	expected := map[int64]PluralForm{
+		0:   PluralMany,
+		1:   PluralOne,
+		2:   PluralFew,
+		4:   PluralFew,
+		5:   PluralMany,
+		11:  PluralMany,
+		12:  PluralMany,
+		14:  PluralMany,
+		21:  PluralOne,
+		22:  PluralFew,
+		111: PluralMany,
+		101: PluralOne,
+		-3:  PluralFew,
+	}
+	for n, form := range expected {
+		require.Equal(t, form, Plural("ru-RU", n), "n = %v", n)
+	}
+}
+
+func TestPluralOneOther(t *testing.T) {
+	require.Equal(t, PluralOne, Plural("kk-KZ", 1))
+	require.Equal(t, PluralOther, Plural("kk-KZ", 2))
+	require.Equal(t, PluralOne, Plural("uz", 1))
+	require.Equal(t, PluralOther, Plural("uz_UZ", 21))
+	require.Equal(t, PluralOther, Plural("xx", 1))
+}
+
+func TestRegisterPluralRule(t *testing.T) {
+	RegisterPluralRule("XX", func(n int64) PluralForm {
+		return PluralMany
+	})
+	require.Equal(t, PluralMany, Plural("xx-YY", 1))
+}
@@ -0,0 +1,210 @@
+package galice
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Built-in intents of Alice NLU
+const (
+	IntentHelp         = "YANDEX.HELP"
+	IntentWhatCanYouDo = "YANDEX.WHAT_CAN_YOU_DO"
+	IntentRepeat       = "YANDEX.REPEAT"
+	IntentConfirm      = "YANDEX.CONFIRM"
+	IntentReject       = "YANDEX.REJECT"
+)
+
+// Storage keys of built-in intents handling
+const (
+	lastResponseKey = "galice.last_response"
+	questionKey     = "galice.question"
+)
+
+// oneShotDirectives are directives which are not replayed with the last response
+var oneShotDirectives = []string{"start_purchase", "start_account_linking"}
+
+// savedResponse is the last response kept in storage for YANDEX.REPEAT intent
+type savedResponse struct {
+	Response         Response        `json:"response"`
+	SessionState     json.RawMessage `json:"session_state,omitempty"`
+	UserStateUpdate  json.RawMessage `json:"user_state_update,omitempty"`
+	ApplicationState json.RawMessage `json:"application_state,omitempty"`
+}
+
+// IntentSlot is a value of intent slot
+type IntentSlot struct {
+	Type   string `json:"type"` // slot entity type, e.g. "YANDEX.NUMBER" or custom entity name
+	Tokens struct {
+		Start uint `json:"start"`
+		End   uint `json:"end"`
+	} `json:"tokens"`
+	Value json.RawMessage `json:"value"`
+}
+
+// Intent is an intent recognized by Alice NLU with values of its slots
+type Intent struct {
+	Slots map[string]IntentSlot `json:"slots"`
+}
+
+// HasIntent checks if Alice NLU recognized intent with provided name in request
+func (n *RequestNLU) HasIntent(name string) bool {
+	_, ok := n.Intents[name]
+	return ok
+}
+
+// YesNoHandler is a handler of answer to the question asked with OutputData.AskYesNo
+type YesNoHandler func(i InputData, question string, yes bool) (OutputData, error)
+
+// AskYesNo marks response as a yes/no question. If the next request has YANDEX.CONFIRM
+// or YANDEX.REJECT intent, it is passed to YesNoHandler (see Client.SetYesNoHandler)
+// with the same question, which is a skill defined name of what was asked.
+func (o *OutputData) AskYesNo(question string) {
+	o.question = question
+}
+
+// SetHelpHandler sets handler for requests with YANDEX.HELP and YANDEX.WHAT_CAN_YOU_DO intents
+func (c *Client) SetHelpHandler(fn AliceHandler) {
+	c.helpHandler = fn
+}
+
+// SetAutoRepeat enables replaying of the last response for requests with YANDEX.REPEAT intent.
+// Responses are kept in Client storage with session, user and application states, one-shot
+// directives (start_purchase, start_account_linking) are not replayed.
+func (c *Client) SetAutoRepeat(enabled bool) {
+	c.autoRepeat = enabled
+}
+
+// SetYesNoHandler sets handler for answers to questions asked with OutputData.AskYesNo.
+// Requests with YANDEX.CONFIRM and YANDEX.REJECT intents without pending question
+// are passed to the main handler.
+func (c *Client) SetYesNoHandler(fn YesNoHandler) {
+	c.yesNoHandler = fn
+}
+
+// routeIntent returns handler for built-in intent of request or nil if request
+// should be passed to the main handler
+func (c *Client) routeIntent(i InputData) (AliceHandler, error) {
+	nlu := &i.Request.NLU
+	switch {
+	case c.helpHandler != nil && (nlu.HasIntent(IntentHelp) || nlu.HasIntent(IntentWhatCanYouDo)):
+		return c.helpHandler, nil
+	case c.autoRepeat && nlu.HasIntent(IntentRepeat):
+		return c.repeatHandler(i)
+	}
+
+	question, err := c.pendingQuestion(i)
+	if err != nil || question == "" {
+		return nil, err
+	}
+	if strings.HasPrefix(question, confirmPrefix) {
+		return c.routeConfirmation(i, question)
+	}
+	if c.yesNoHandler != nil && (nlu.HasIntent(IntentConfirm) || nlu.HasIntent(IntentReject)) {
+		yes := nlu.HasIntent(IntentConfirm)
+		return func(i InputData) (OutputData, error) {
+			return c.yesNoHandler(i, question, yes)
+		}, nil
+	}
+	return nil, nil
+}
+
+// tracksQuestions checks if pending yes/no questions should be kept in storage
+func (c *Client) tracksQuestions() bool {
+	return c.yesNoHandler != nil || c.hasConfirmations()
+}
+
+// repeatHandler returns handler replaying the last response
+func (c *Client) repeatHandler(i InputData) (AliceHandler, error) {
+	data, err := c.storage.Get(i.Session.SessionID, lastResponseKey)
+	if err != nil || data == nil {
+		return nil, err
+	}
+	var last savedResponse
+	if err = json.Unmarshal(data, &last); err != nil {
+		return nil, err
+	}
+	question, err := c.pendingQuestion(i)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(i InputData) (OutputData, error) {
+		o := NewOutput(i, last.Response)
+		if last.SessionState != nil {
+			o.SessionState = last.SessionState
+		}
+		if last.UserStateUpdate != nil {
+			o.UserStateUpdate = last.UserStateUpdate
+		}
+		if last.ApplicationState != nil {
+			o.ApplicationState = last.ApplicationState
+		}
+		o.AskYesNo(question)
+		return o, nil
+	}, nil
+}
+
+// pendingQuestion returns question asked in the last response
+func (c *Client) pendingQuestion(i InputData) (string, error) {
+	if !c.tracksQuestions() {
+		return "", nil
+	}
+	data, err := c.storage.Get(i.Session.SessionID, questionKey)
+	return string(data), err
+}
+
+// saveIntentsState saves the last response and pending question for handling built-in intents
+func (c *Client) saveIntentsState(i InputData, o OutputData) error {
+	if c.autoRepeat {
+		data, err := marshalLastResponse(o)
+		if err != nil {
+			return fmt.Errorf("Unable to save last response: %v", err)
+		}
+		if err = c.storage.Set(i.Session.SessionID, lastResponseKey, data); err != nil {
+			return fmt.Errorf("Unable to save last response: %v", err)
+		}
+	}
+	if c.tracksQuestions() {
+		var data []byte
+		if o.question != "" {
+			data = []byte(o.question)
+		}
+		if err := c.storage.Set(i.Session.SessionID, questionKey, data); err != nil {
+			return fmt.Errorf("Unable to save pending question: %v", err)
+		}
+	}
+	return nil
+}
+
+// marshalLastResponse encodes response and states of o without one-shot directives
+func marshalLastResponse(o OutputData) ([]byte, error) {
+	last := savedResponse{Response: o.Response}
+	if len(o.Response.Directives) > 0 {
+		last.Response.Directives = make(map[string]interface{}, len(o.Response.Directives))
+		for name, d := range o.Response.Directives {
+			last.Response.Directives[name] = d
+		}
+		for _, name := range oneShotDirectives {
+			delete(last.Response.Directives, name)
+		}
+	}
+
+	var err error
+	for _, s := range []struct {
+		dst *json.RawMessage
+		src interface{}
+	}{
+		{&last.SessionState, o.SessionState},
+		{&last.UserStateUpdate, o.UserStateUpdate},
+		{&last.ApplicationState, o.ApplicationState},
+	} {
+		if s.src == nil {
+			continue
+		}
+		if *s.dst, err = json.Marshal(s.src); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(last)
+}
@@ -0,0 +1,143 @@
+package galice
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntents(t *testing.T) {
+	var nlu RequestNLU
+	err := json.Unmarshal([]byte(`{
+		"tokens": ["включи", "свет", "на", "кухне"],
+		"intents": {
+			"turn.on": {"slots": {"room": {"type": "YANDEX.STRING", "tokens": {"start": 3, "end": 4}, "value": "кухня"}}},
+			"YANDEX.CONFIRM": {"slots": {}}
+		}
+	}`), &nlu)
+	require.NoError(t, err)
+	require.True(t, nlu.HasIntent("turn.on"))
+	require.True(t, nlu.HasIntent(IntentConfirm))
+	require.False(t, nlu.HasIntent(IntentReject))
+
+	slot := nlu.Intents["turn.on"].Slots["room"]
+	require.Equal(t, "YANDEX.STRING", slot.Type)
+	require.Equal(t, uint(3), slot.Tokens.Start)
+	require.Equal(t, `"кухня"`, string(slot.Value))
+}
+
+func TestBuiltInIntents(t *testing.T) {
+	cli := New(true, true)
+	cli.SetAutoRepeat(true)
+	cli.SetHelpHandler(func(i InputData) (OutputData, error) {
+		return NewOutput(i, NewResponse("Я умею заказывать пиццу", "", false)), nil
+	})
+	var answers []bool
+	cli.SetYesNoHandler(func(i InputData, question string, yes bool) (OutputData, error) {
+		require.Equal(t, "order", question)
+		answers = append(answers, yes)
+		return NewOutput(i, NewResponse("Принято", "", false)), nil
+	})
+
+	h := cli.CreateHandler(func(i InputData) (OutputData, error) {
+		o := NewOutput(i, NewResponse("Заказать пиццу "+i.Request.Command+"?", "", false))
+		o.AskYesNo("order")
+		return o, nil
+	})
+	send := func(command, intent string) string {
+		body := `{"request": {"command": "` + command + `", "type": "SimpleUtterance", "nlu": {"intents": {"` + intent + `": {"slots": {}}}}}, "session": {"session_id": "1"}}`
+		req, err := http.NewRequest("POST", "/skill", bytes.NewReader([]byte(body)))
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var o OutputData
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &o))
+		return o.Response.Text
+	}
+
+	// there is nothing to repeat and no pending question yet
+	require.Equal(t, "Заказать пиццу повтори?", send("повтори", IntentRepeat))
+	require.Empty(t, answers)
+
+	require.Equal(t, "Заказать пиццу маргарита?", send("маргарита", "order"))
+	require.Equal(t, "Я умею заказывать пиццу", send("что ты умеешь", IntentWhatCanYouDo))
+	require.Equal(t, "Я умею заказывать пиццу", send("повтори", IntentRepeat))
+
+	require.Equal(t, "Заказать пиццу пепперони?", send("пепперони", "order"))
+	require.Equal(t, "Заказать пиццу пепперони?", send("еще раз", IntentRepeat))
+	require.Equal(t, "Принято", send("да", IntentConfirm))
+	require.Equal(t, []bool{true}, answers)
+
+	// question is answered, so the next confirmation goes to the main handler
+	require.Equal(t, "Заказать пиццу да?", send("да", IntentConfirm))
+	require.Equal(t, "Принято", send("нет", IntentReject))
+	require.Equal(t, []bool{true, false}, answers)
+}
+
+func TestRepeatState(t *testing.T) {
+	cli := New(true, true)
+	cli.SetAutoRepeat(true)
+
+	h := cli.CreateHandler(func(i InputData) (OutputData, error) {
+		r := NewResponse("Оплатите пакет", "", false)
+		r.StartPurchase(PurchaseDirective{PurchaseRequestID: "1"})
+		r.Directives["audio_player"] = map[string]string{"action": "Play"}
+		o := NewOutput(i, r)
+		o.SessionState = map[string]int{"step": 2}
+		o.UserStateUpdate = map[string]string{"name": "Вася"}
+		o.ApplicationState = map[string]bool{"seen": true}
+		return o, nil
+	})
+	send := func(intent string) OutputData {
+		body := `{"request": {"command": "", "type": "SimpleUtterance", "nlu": {"intents": {"` + intent + `": {"slots": {}}}}}, "session": {"session_id": "1"}}`
+		req, err := http.NewRequest("POST", "/skill", bytes.NewReader([]byte(body)))
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var o OutputData
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &o))
+		return o
+	}
+
+	o := send("buy")
+	require.Contains(t, o.Response.Directives, "start_purchase")
+
+	o = send(IntentRepeat)
+	require.Equal(t, "Оплатите пакет", o.Response.Text)
+	require.NotContains(t, o.Response.Directives, "start_purchase")
+	require.Contains(t, o.Response.Directives, "audio_player")
+	require.Equal(t, map[string]interface{}{"step": 2.0}, o.SessionState)
+	require.Equal(t, map[string]interface{}{"name": "Вася"}, o.UserStateUpdate)
+	require.Equal(t, map[string]interface{}{"seen": true}, o.ApplicationState)
+}
+
+func TestIntentsMiddlewares(t *testing.T) {
+	cli := New(true, true)
+	cli.SetAutoRepeat(true)
+	cli.SetHelpHandler(func(i InputData) (OutputData, error) {
+		return NewOutput(i, NewResponse("Я умею заказывать пиццу", "", false)), nil
+	})
+	cli.Use(TrackSessionEvents)
+
+	h := cli.CreateHandler(func(i InputData) (OutputData, error) {
+		return NewOutput(i, NewResponse("Какую пиццу?", "", false)), nil
+	})
+	body := `{"request": {"command": "помощь", "type": "SimpleUtterance", "nlu": {"intents": {"YANDEX.HELP": {"slots": {}}}}}, "session": {"session_id": "1", "new": true}}`
+	req, err := http.NewRequest("POST", "/skill", bytes.NewReader([]byte(body)))
+	require.NoError(t, err)
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var o OutputData
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &o))
+	require.Equal(t, "Я умею заказывать пиццу", o.Response.Text)
+	require.NotNil(t, o.Analytics)
+	require.Equal(t, EventSessionStarted, o.Analytics.Events[0].Name)
+}
@@ -0,0 +1,17 @@
+// Package plural provides plural rules shared by galice packages
+package plural
+
+// Russian returns index of russian plural form of n: 0 for "1 рубль", 1 for "2 рубля", 2 for "5 рублей".
+// The same rule is used for ukrainian and belarusian.
+func Russian(n int64) int {
+	if n < 0 {
+		n = -n
+	}
+	switch {
+	case n%10 == 1 && n%100 != 11:
+		return 0
+	case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+		return 1
+	}
+	return 2
+}
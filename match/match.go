@@ -0,0 +1,187 @@
+// Package match provides fuzzy matching of user commands for russian language.
+// It works over words of Alice request (RequestNLU.Tokens) and tolerates
+// word order, inflection ("пиццу", "пиццы", "пицца"), stop words and ASR typos.
+package match
+
+import (
+	"strings"
+	"unicode"
+)
+
+// DefaultStopWords are words ignored by Matcher by default
+var DefaultStopWords = []string{
+	"а", "и", "в", "во", "на", "с", "со", "к", "ко", "у", "о", "об", "по", "за", "из", "от", "до",
+	"же", "ли", "бы", "ну", "вот", "это", "так", "то", "ка", "мне", "меня", "пожалуйста",
+	"давай", "давайте", "алиса", "хочу", "можно", "выбери", "выбираю",
+}
+
+// Matcher compares user input with expected phrases
+type Matcher struct {
+	stopWords   map[string]bool
+	synonyms    map[string]string // canonical stem by stem
+	maxDistance int               // maximum Levenshtein distance between similar stems
+	threshold   float64           // minimum score of matching phrase
+}
+
+// New creates Matcher with DefaultStopWords, distance tolerance 1 and threshold 0.5
+func New() *Matcher {
+	m := &Matcher{
+		stopWords:   map[string]bool{},
+		synonyms:    map[string]string{},
+		maxDistance: 1,
+		threshold:   0.5,
+	}
+	m.AddStopWords(DefaultStopWords...)
+	return m
+}
+
+// AddStopWords adds words ignored while matching
+func (m *Matcher) AddStopWords(words ...string) {
+	for _, w := range words {
+		m.stopWords[normalizeWord(w)] = true
+	}
+}
+
+// AddSynonyms adds set of words treated as the same word while matching
+func (m *Matcher) AddSynonyms(words ...string) {
+	if len(words) == 0 {
+		return
+	}
+	canonical := m.canonical(Stem(words[0]))
+	for _, w := range words[1:] {
+		m.synonyms[Stem(w)] = canonical
+	}
+}
+
+// SetMaxDistance sets maximum Levenshtein distance between stems treated as the same.
+// Short stems (up to 3 letters) must match exactly, zero value disables typos tolerance.
+func (m *Matcher) SetMaxDistance(d int) {
+	m.maxDistance = d
+}
+
+// SetThreshold sets minimum score (from 0 to 1) for Match and Best
+func (m *Matcher) SetThreshold(t float64) {
+	m.threshold = t
+}
+
+// Threshold returns minimum score for Match and Best
+func (m *Matcher) Threshold() float64 {
+	return m.threshold
+}
+
+// Normalize converts words into stems without stop words, synonyms are replaced with
+// the first word of their set
+func (m *Matcher) Normalize(tokens []string) []string {
+	res := make([]string, 0, len(tokens))
+	for _, t := range tokens {
+		w := normalizeWord(t)
+		if w == "" || m.stopWords[w] {
+			continue
+		}
+		res = append(res, m.canonical(Stem(w)))
+	}
+	return res
+}
+
+// Score returns similarity of words and phrase from 0 (nothing in common) to 1 (the same words
+// in any order and form)
+func (m *Matcher) Score(tokens []string, phrase string) float64 {
+	a := m.Normalize(tokens)
+	b := m.Normalize(Tokenize(phrase))
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	used := make([]bool, len(b))
+	matched := 0
+	for _, x := range a {
+		for n, y := range b {
+			if !used[n] && m.similar(x, y) {
+				used[n] = true
+				matched++
+				break
+			}
+		}
+	}
+
+	return float64(2*matched) / float64(len(a)+len(b))
+}
+
+// Match checks if words match phrase with score not less than threshold
+func (m *Matcher) Match(tokens []string, phrase string) bool {
+	return m.Score(tokens, phrase) >= m.threshold
+}
+
+// Best returns index and score of option best matching words. If there is no option
+// with score not less than threshold -1 is returned.
+func (m *Matcher) Best(tokens []string, options ...string) (int, float64) {
+	best, bestScore := -1, 0.0
+	for n, o := range options {
+		if score := m.Score(tokens, o); score >= m.threshold && score > bestScore {
+			best, bestScore = n, score
+		}
+	}
+	return best, bestScore
+}
+
+func (m *Matcher) canonical(stem string) string {
+	if c, ok := m.synonyms[stem]; ok {
+		return c
+	}
+	return stem
+}
+
+func (m *Matcher) similar(a, b string) bool {
+	if a == b {
+		return true
+	}
+	if m.maxDistance == 0 || (len([]rune(a)) <= 3 && len([]rune(b)) <= 3) {
+		return false
+	}
+	return Levenshtein(a, b) <= m.maxDistance
+}
+
+// Tokenize splits phrase into lower case words without punctuation
+func Tokenize(phrase string) []string {
+	return strings.FieldsFunc(strings.ToLower(phrase), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Levenshtein returns edit distance between a and b
+func Levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for k := range prev {
+		prev[k] = k
+	}
+	for n := 1; n <= len(ra); n++ {
+		cur[0] = n
+		for k := 1; k <= len(rb); k++ {
+			cost := 1
+			if ra[n-1] == rb[k-1] {
+				cost = 0
+			}
+			cur[k] = min(prev[k]+1, cur[k-1]+1, prev[k-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+func min(values ...int) int {
+	res := values[0]
+	for _, v := range values[1:] {
+		if v < res {
+			res = v
+		}
+	}
+	return res
+}
+
+func normalizeWord(w string) string {
+	return strings.Replace(strings.ToLower(strings.TrimFunc(w, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})), "ё", "е", -1)
+}
@@ -0,0 +1,61 @@
+package match
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLevenshtein(t *testing.T) {
+	require.Equal(t, 0, Levenshtein("пицца", "пицца"))
+	require.Equal(t, 1, Levenshtein("пицца", "пица"))
+	require.Equal(t, 2, Levenshtein("кот", "кит "))
+	require.Equal(t, 3, Levenshtein("", "кот"))
+}
+
+func TestTokenize(t *testing.T) {
+	require.Equal(t, []string{"закажи", "пиццу", "на", "завтра"}, Tokenize("Закажи пиццу, на завтра!"))
+}
+
+func TestScore(t *testing.T) {
+	m := New()
+	require.Equal(t, 1.0, m.Score([]string{"пиццу", "маргариту"}, "Пицца Маргарита"))
+	require.Equal(t, 1.0, m.Score([]string{"маргариту", "пиццу", "пожалуйста"}, "Пицца Маргарита"))
+	require.Equal(t, 1.0, m.Score([]string{"пица", "маргарита"}, "Пицца Маргарита"))
+	require.Equal(t, 0.0, m.Score([]string{"суши"}, "Пицца Маргарита"))
+	require.Equal(t, 0.0, m.Score([]string{"пожалуйста"}, "Пицца Маргарита"))
+
+	m.SetMaxDistance(0)
+	require.Equal(t, 0.5, m.Score([]string{"пица", "маргарита"}, "Пицца Маргарита"))
+}
+
+func TestSynonymsAndStopWords(t *testing.T) {
+	m := New()
+	require.False(t, m.Match([]string{"газировка"}, "лимонад"))
+	m.AddSynonyms("лимонад", "газировка", "газировку")
+	require.True(t, m.Match([]string{"газировку"}, "лимонад"))
+
+	require.Equal(t, 2.0/3.0, m.Score([]string{"большой", "лимонад"}, "лимонад"))
+	m.AddStopWords("большой")
+	require.Equal(t, 1.0, m.Score([]string{"большой", "лимонад"}, "лимонад"))
+}
+
+func TestBest(t *testing.T) {
+	m := New()
+	options := []string{"Пицца Маргарита", "Пицца Пепперони", "Суши"}
+
+	n, score := m.Best(Tokenize("давай пепперони"), options...)
+	require.Equal(t, 1, n)
+	require.InDelta(t, 2.0/3.0, score, 0.0001)
+
+	n, _ = m.Best(Tokenize("выбираю суши"), options...)
+	require.Equal(t, 2, n)
+
+	n, score = m.Best(Tokenize("борщ"), options...)
+	require.Equal(t, -1, n)
+	require.Equal(t, 0.0, score)
+
+	m.SetThreshold(0.9)
+	n, _ = m.Best(Tokenize("пепперони"), options...)
+	require.Equal(t, -1, n)
+}
@@ -0,0 +1,170 @@
+package match
+
+import "strings"
+
+// suffix is an ending removed by stemmer. Endings with afterAYa flag
+// are removed only if preceded by а or я.
+type suffix struct {
+	value    []rune
+	afterAYa bool
+}
+
+func suffixes(afterAYa []string, other []string) []suffix {
+	res := make([]suffix, 0, len(afterAYa)+len(other))
+	for _, s := range afterAYa {
+		res = append(res, suffix{[]rune(s), true})
+	}
+	for _, s := range other {
+		res = append(res, suffix{[]rune(s), false})
+	}
+	return res
+}
+
+var (
+	perfectiveGerund = suffixes(
+		[]string{"в", "вши", "вшись"},
+		[]string{"ив", "ивши", "ившись", "ыв", "ывши", "ывшись"},
+	)
+	reflexive = suffixes(nil, []string{"ся", "сь"})
+	adjective = suffixes(nil, []string{
+		"ее", "ие", "ые", "ое", "ими", "ыми", "ей", "ий", "ый", "ой", "ем", "им", "ым", "ом",
+		"его", "ого", "ему", "ому", "их", "ых", "ую", "юю", "ая", "яя", "ою", "ею",
+	})
+	participle = suffixes(
+		[]string{"ем", "нн", "вш", "ющ", "щ"},
+		[]string{"ивш", "ывш", "ующ"},
+	)
+	verb = suffixes(
+		[]string{"ла", "на", "ете", "йте", "ли", "й", "л", "ем", "н", "ло", "но", "ет", "ют", "ны", "ть", "ешь", "нно"},
+		[]string{
+			"ила", "ыла", "ена", "ейте", "уйте", "ите", "или", "ыли", "ей", "уй", "ил", "ыл", "им", "ым", "ен",
+			"ило", "ыло", "ено", "ят", "ует", "уют", "ит", "ыт", "ены", "ить", "ыть", "ишь", "ую", "ю",
+		},
+	)
+	noun = suffixes(nil, []string{
+		"а", "ев", "ов", "ие", "ье", "е", "иями", "ями", "ами", "еи", "ии", "и", "ией", "ей", "ой", "ий", "й",
+		"иям", "ям", "ием", "ем", "ам", "ом", "о", "у", "ах", "иях", "ях", "ы", "ь", "ию", "ью", "ю", "ия", "ья", "я",
+	})
+	superlative   = suffixes(nil, []string{"ейше", "ейш"})
+	derivational  = suffixes(nil, []string{"ость", "ост"})
+	russianVowels = "аеиоуыэюя"
+)
+
+// Stem returns stem of russian word using Snowball (Porter) algorithm,
+// so different forms of the word have the same stem: "пиццу", "пиццы", "пицца" -> "пицц".
+// Word is converted to lower case and ё is replaced with е.
+func Stem(word string) string {
+	w := []rune(strings.Replace(strings.ToLower(word), "ё", "е", -1))
+	rv, r2 := regions(w)
+	if rv >= len(w) {
+		return string(w)
+	}
+
+	// Step 1
+	if n, ok := removeSuffix(w, rv, perfectiveGerund); ok {
+		w = w[:n]
+	} else {
+		if n, ok := removeSuffix(w, rv, reflexive); ok {
+			w = w[:n]
+		}
+		if n, ok := removeSuffix(w, rv, adjective); ok {
+			w = w[:n]
+			if n, ok := removeSuffix(w, rv, participle); ok {
+				w = w[:n]
+			}
+		} else if n, ok := removeSuffix(w, rv, verb); ok {
+			w = w[:n]
+		} else if n, ok := removeSuffix(w, rv, noun); ok {
+			w = w[:n]
+		}
+	}
+
+	// Step 2
+	if len(w) > rv && w[len(w)-1] == 'и' {
+		w = w[:len(w)-1]
+	}
+
+	// Step 3
+	if n, ok := removeSuffix(w, r2, derivational); ok {
+		w = w[:n]
+	}
+
+	// Step 4
+	switch {
+	case hasSuffix(w, rv, []rune("нн")):
+		w = w[:len(w)-1]
+	case hasSuffix(w, rv, []rune("ь")):
+		w = w[:len(w)-1]
+	default:
+		if n, ok := removeSuffix(w, rv, superlative); ok {
+			w = w[:n]
+			if hasSuffix(w, rv, []rune("нн")) {
+				w = w[:len(w)-1]
+			}
+		}
+	}
+
+	return string(w)
+}
+
+// regions returns start positions of RV and R2 regions of word
+func regions(w []rune) (int, int) {
+	rv, r1, r2 := len(w), len(w), len(w)
+	for n := 0; n < len(w); n++ {
+		if isVowel(w[n]) {
+			rv = n + 1
+			break
+		}
+	}
+	for n := 1; n < len(w); n++ {
+		if !isVowel(w[n]) && isVowel(w[n-1]) {
+			r1 = n + 1
+			break
+		}
+	}
+	for n := r1 + 1; n < len(w); n++ {
+		if !isVowel(w[n]) && isVowel(w[n-1]) {
+			r2 = n + 1
+			break
+		}
+	}
+	return rv, r2
+}
+
+func isVowel(r rune) bool {
+	return strings.ContainsRune(russianVowels, r)
+}
+
+// removeSuffix finds the longest suffix of w from list within region starting from start
+// and returns length of w without it
+func removeSuffix(w []rune, start int, list []suffix) (int, bool) {
+	var found *suffix
+	for n := range list {
+		s := &list[n]
+		if hasSuffix(w, start, s.value) && (found == nil || len(s.value) > len(found.value)) {
+			found = s
+		}
+	}
+	if found == nil {
+		return len(w), false
+	}
+
+	n := len(w) - len(found.value)
+	if found.afterAYa && (n-1 < start || (w[n-1] != 'а' && w[n-1] != 'я')) {
+		return len(w), false
+	}
+	return n, true
+}
+
+func hasSuffix(w []rune, start int, s []rune) bool {
+	n := len(w) - len(s)
+	if n < start || n < 0 {
+		return false
+	}
+	for k := range s {
+		if w[n+k] != s[k] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,29 @@
+package match
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStem(t *testing.T) {
+	expected := map[string]string{
+		"пицца":              "пицц",
+		"пиццу":              "пицц",
+		"пиццы":              "пицц",
+		"Пиццей":             "пицц",
+		"красивая":           "красив",
+		"красивого":          "красив",
+		"закажи":             "закаж",
+		"заказать":           "заказа",
+		"прочитавшись":       "прочита",
+		"ёлка":               "елк",
+		"производительность": "производительн",
+		"длиннейший":         "длин",
+		"да":                 "да",
+		"кот":                "кот",
+	}
+	for word, stem := range expected {
+		require.Equal(t, stem, Stem(word), "word = %v", word)
+	}
+}
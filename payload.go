@@ -0,0 +1,98 @@
+package galice
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// AddPayloadButton adds new button with typed payload into response
+func AddPayloadButton[T any](r *Response, title string, hide bool, URL string, payload T) {
+	r.AddButton(title, hide, URL, payload)
+}
+
+// PayloadAs decodes payload of request into value of type T
+func PayloadAs[T any](r Request) (T, error) {
+	var v T
+	err := r.DecodePayload(&v)
+	return v, err
+}
+
+// PayloadHandler is a signature of handler for ButtonPressed requests with payload of type T
+type PayloadHandler[T any] func(InputData, T) (OutputData, error)
+
+// taggedPayload is a button payload with discriminator of its type
+type taggedPayload struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// PayloadRegistry keeps typed payload handlers. Payloads of buttons created with
+// AddTaggedButton are tagged with name of their type, so the registry can dispatch
+// ButtonPressed requests to the handler of that type.
+type PayloadRegistry struct {
+	names    map[reflect.Type]string
+	decoders map[string]func(json.RawMessage) (AliceHandler, error)
+}
+
+// NewPayloadRegistry creates new empty PayloadRegistry
+func NewPayloadRegistry() *PayloadRegistry {
+	return &PayloadRegistry{
+		names:    map[reflect.Type]string{},
+		decoders: map[string]func(json.RawMessage) (AliceHandler, error){},
+	}
+}
+
+// RegisterPayload registers handler for payloads of type T tagged with name.
+// Name is sent to Alice API within payload, so it must be stable between skill releases.
+// Requests with payload which can't be decoded into T are passed to the next handler of registry.
+func RegisterPayload[T any](reg *PayloadRegistry, name string, fn PayloadHandler[T]) {
+	reg.names[reflect.TypeOf((*T)(nil)).Elem()] = name
+	reg.decoders[name] = func(data json.RawMessage) (AliceHandler, error) {
+		var v T
+		if err := json.Unmarshal(data, &v); err != nil {
+			return nil, fmt.Errorf("Unable to decode %v payload: %v", name, err)
+		}
+		return func(i InputData) (OutputData, error) { return fn(i, v) }, nil
+	}
+}
+
+// AddTaggedButton adds new button with payload tagged with name of its type into response.
+// Payload type must be registered with RegisterPayload.
+func AddTaggedButton[T any](reg *PayloadRegistry, r *Response, title string, hide bool, payload T) error {
+	name, ok := reg.names[reflect.TypeOf((*T)(nil)).Elem()]
+	if !ok {
+		return fmt.Errorf("Unregistered payload type: %T", payload)
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("Unable to encode %v payload: %v", name, err)
+	}
+	r.AddButton(title, hide, "", taggedPayload{name, data})
+	return nil
+}
+
+// Handler creates AliceHandler which dispatches ButtonPressed requests with tagged payloads
+// to registered handlers. Other requests and requests with undecodable payloads are passed
+// to next, in the latter case decoding error is returned along with response of next for logging.
+func (reg *PayloadRegistry) Handler(next AliceHandler) AliceHandler {
+	return func(i InputData) (OutputData, error) {
+		if i.Request.Type == RequestTypeButtonPressed {
+			var p taggedPayload
+			if err := json.Unmarshal(i.Request.Payload, &p); err == nil {
+				if decode, ok := reg.decoders[p.Type]; ok {
+					fn, err := decode(p.Data)
+					if err == nil {
+						return fn(i)
+					}
+					o, nextErr := next(i)
+					if nextErr != nil {
+						return o, nextErr
+					}
+					return o, err
+				}
+			}
+		}
+		return next(i)
+	}
+}
@@ -0,0 +1,70 @@
+package galice
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testPizza struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type testDrink struct {
+	Name string `json:"name"`
+}
+
+func TestPayloadButton(t *testing.T) {
+	r := NewResponse("test", "", false)
+	AddPayloadButton(&r, "Маргарита", true, "", testPizza{1, "margarita"})
+
+	data, err := json.Marshal(r.Buttons[0].Payload)
+	require.NoError(t, err)
+
+	p, err := PayloadAs[testPizza](Request{Payload: data})
+	require.NoError(t, err)
+	require.Equal(t, testPizza{1, "margarita"}, p)
+
+	_, err = PayloadAs[testPizza](Request{Payload: json.RawMessage(`[1, 2]`)})
+	require.Error(t, err)
+}
+
+func TestPayloadRegistry(t *testing.T) {
+	reg := NewPayloadRegistry()
+	RegisterPayload(reg, "pizza", func(i InputData, p testPizza) (OutputData, error) {
+		return NewOutput(i, NewResponse("pizza "+p.Name, "", false)), nil
+	})
+	RegisterPayload(reg, "drink", func(i InputData, p testDrink) (OutputData, error) {
+		return NewOutput(i, NewResponse("drink "+p.Name, "", false)), nil
+	})
+	h := reg.Handler(func(i InputData) (OutputData, error) {
+		return NewOutput(i, NewResponse("default", "", false)), nil
+	})
+
+	r := NewResponse("test", "", false)
+	require.NoError(t, AddTaggedButton(reg, &r, "Маргарита", true, testPizza{1, "margarita"}))
+	require.NoError(t, AddTaggedButton(reg, &r, "Кола", true, testDrink{"cola"}))
+	require.Error(t, AddTaggedButton(reg, &r, "Суши", true, "sushi"))
+	require.Len(t, r.Buttons, 2)
+
+	press := func(b ResponseButton) string {
+		data, err := json.Marshal(b.Payload)
+		require.NoError(t, err)
+		o, err := h(InputData{Request: Request{Type: RequestTypeButtonPressed, Payload: data}})
+		require.NoError(t, err)
+		return o.Response.Text
+	}
+	require.Equal(t, "pizza margarita", press(r.Buttons[0]))
+	require.Equal(t, "drink cola", press(r.Buttons[1]))
+	require.Equal(t, "default", press(ResponseButton{Payload: map[string]string{"type": "sushi"}}))
+
+	o, err := h(InputData{Request: Request{Type: RequestTypeButtonPressed, Payload: json.RawMessage(`{"type": "pizza", "data": {"id": "one"}}`)}})
+	require.Error(t, err)
+	require.Equal(t, "default", o.Response.Text)
+
+	o, err = h(InputData{Request: Request{Type: RequestTypeSimpleUtterance}})
+	require.NoError(t, err)
+	require.Equal(t, "default", o.Response.Text)
+}
@@ -0,0 +1,149 @@
+package galice
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// PurchaseProduct is a product of in-skill purchase
+type PurchaseProduct struct {
+	ProductID string  `json:"product_id"`
+	Title     string  `json:"title"`
+	UserPrice float64 `json:"user_price"` // price with discount
+	Price     float64 `json:"price"`      // price without discount
+	NDSType   string  `json:"nds_type"`   // VAT type, e.g. "nds_none" or "nds_20"
+	Quantity  float64 `json:"quantity"`
+}
+
+// PurchaseDirective is a start_purchase directive which asks user to pay for products
+type PurchaseDirective struct {
+	PurchaseRequestID string            `json:"purchase_request_id"` // skill defined purchase ID
+	ImageURL          string            `json:"image_url,omitempty"`
+	Caption           string            `json:"caption"`
+	Description       string            `json:"description"`
+	Currency          string            `json:"currency"` // e.g. "RUB"
+	Type              string            `json:"type"`     // only "BUY" is supported by Alice API
+	Payload           interface{}       `json:"payload,omitempty"`
+	MerchantKey       string            `json:"merchant_key"`
+	Products          []PurchaseProduct `json:"products"`
+}
+
+// StartPurchase adds start_purchase directive into current response.
+// Alice sends Purchase.Confirmation request after user pays for products.
+func (r *Response) StartPurchase(d PurchaseDirective) {
+	if d.Type == "" {
+		d.Type = "BUY"
+	}
+	if r.Directives == nil {
+		r.Directives = map[string]interface{}{}
+	}
+	r.Directives["start_purchase"] = d
+}
+
+// DecodePurchasePayload decodes payload of Purchase.Confirmation request into provided variable
+func (r *Request) DecodePurchasePayload(p interface{}) error {
+	if err := json.Unmarshal(r.PurchasePayload, p); err != nil {
+		return fmt.Errorf("Unable to decode purchase payload: %v", err)
+	}
+	return nil
+}
+
+// ParsePurchasePublicKey parses PEM encoded RSA public key used for verification of purchases
+func ParsePurchasePublicKey(data []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("Unable to decode purchase public key: no PEM data found")
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		if rsaKey, rsaErr := x509.ParsePKCS1PublicKey(block.Bytes); rsaErr == nil {
+			return rsaKey, nil
+		}
+		return nil, fmt.Errorf("Unable to decode purchase public key: %v", err)
+	}
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("Unable to decode purchase public key: unsupported key type %T", key)
+	}
+	return rsaKey, nil
+}
+
+// VerifyPurchase checks that SignedData of Purchase.Confirmation request is signed
+// with private key matching provided public key (RSA PKCS #1 v1.5 with SHA-256),
+// and that purchase_request_id, purchase_token, order_id and purchase_payload
+// of SignedData are the same as in request, so signed data of another purchase cannot be replayed
+func VerifyPurchase(key *rsa.PublicKey, r Request) error {
+	if !r.IsPurchaseConfirmation() {
+		return fmt.Errorf("Cannot verify purchase for request type %v", r.Type)
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(r.Signature)
+	if err != nil {
+		return fmt.Errorf("Unable to decode purchase signature: %v", err)
+	}
+	hash := sha256.Sum256([]byte(r.SignedData))
+	if err = rsa.VerifyPKCS1v15(key, crypto.SHA256, hash[:], sig); err != nil {
+		return fmt.Errorf("Invalid purchase signature: %v", err)
+	}
+
+	signed := parseSignedData(r.SignedData)
+	for _, f := range []struct{ name, value string }{
+		{"purchase_request_id", r.PurchaseRequestID},
+		{"purchase_token", r.PurchaseToken},
+		{"order_id", r.OrderID},
+	} {
+		if v, ok := signed[f.name]; !ok || v != f.value {
+			return fmt.Errorf("Invalid purchase: %v does not match signed data", f.name)
+		}
+	}
+	if !samePayload(signed["purchase_payload"], r.PurchasePayload) {
+		return fmt.Errorf("Invalid purchase: purchase_payload does not match signed data")
+	}
+	return nil
+}
+
+// signedDataFields are fields of SignedData, "key=value" pairs joined with "&"
+var signedDataFields = map[string]bool{
+	"purchase_request_id": true,
+	"purchase_token":      true,
+	"order_id":            true,
+	"purchase_timestamp":  true,
+	"purchase_payload":    true,
+}
+
+// parseSignedData splits SignedData into fields. Values are not escaped by Alice,
+// so "&" not followed by a known field name is a part of value (e.g. of JSON payload).
+func parseSignedData(data string) map[string]string {
+	res := map[string]string{}
+	var name string
+	for n, part := range strings.Split(data, "&") {
+		if eq := strings.IndexByte(part, '='); eq > 0 && signedDataFields[part[:eq]] {
+			name = part[:eq]
+			res[name] = part[eq+1:]
+		} else if n > 0 && name != "" {
+			res[name] += "&" + part
+		}
+	}
+	return res
+}
+
+// samePayload compares JSON payloads ignoring formatting, missing payload equals to null
+func samePayload(a string, b []byte) bool {
+	var x, y interface{}
+	if a != "" && json.Unmarshal([]byte(a), &x) != nil {
+		return false
+	}
+	if len(b) > 0 && json.Unmarshal(b, &y) != nil {
+		return false
+	}
+	return reflect.DeepEqual(x, y)
+}
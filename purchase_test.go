@@ -0,0 +1,151 @@
+package galice
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newPurchaseKey(t *testing.T) (*rsa.PrivateKey, []byte) {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "purchase_private.pem"))
+	require.NoError(t, err)
+	block, _ := pem.Decode(data)
+	require.NotNil(t, block)
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	require.NoError(t, err)
+
+	pub, err := ioutil.ReadFile(filepath.Join("testdata", "purchase_public.pem"))
+	require.NoError(t, err)
+	return key, pub
+}
+
+func signPurchase(t *testing.T, key *rsa.PrivateKey, data string) string {
+	hash := sha256.Sum256([]byte(data))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hash[:])
+	require.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(sig)
+}
+
+func TestStartPurchase(t *testing.T) {
+	r := NewResponse("Оплатите пакет вопросов", "", false)
+	r.StartPurchase(PurchaseDirective{
+		PurchaseRequestID: "d432de19be8347d09f656d9fe966e1b4",
+		Caption:           "Пакет вопросов",
+		Description:       "100 вопросов о космосе",
+		Currency:          "RUB",
+		Payload:           map[string]string{"pack": "space"},
+		MerchantKey:       "merchant",
+		Products: []PurchaseProduct{
+			{ProductID: "space", Title: "Космос", UserPrice: 99, Price: 149, NDSType: "nds_none", Quantity: 1},
+		},
+	})
+
+	data, err := json.Marshal(r.Directives)
+	require.NoError(t, err)
+	require.Equal(t, `{"start_purchase":{"purchase_request_id":"d432de19be8347d09f656d9fe966e1b4","caption":"Пакет вопросов",`+
+		`"description":"100 вопросов о космосе","currency":"RUB","type":"BUY","payload":{"pack":"space"},"merchant_key":"merchant",`+
+		`"products":[{"product_id":"space","title":"Космос","user_price":99,"price":149,"nds_type":"nds_none","quantity":1}]}}`, string(data))
+}
+
+func TestVerifyPurchase(t *testing.T) {
+	key, pubPEM := newPurchaseKey(t)
+	pub, err := ParsePurchasePublicKey(pubPEM)
+	require.NoError(t, err)
+
+	r := Request{
+		Type:              RequestTypePurchaseConfirmation,
+		PurchaseRequestID: "d432de19be8347d09f656d9fe966e1b4",
+		PurchaseToken:     "abc",
+		OrderID:           "123",
+		PurchasePayload:   json.RawMessage(`{"pack": "space", "q": "a&b"}`),
+		SignedData: "purchase_request_id=d432de19be8347d09f656d9fe966e1b4&purchase_token=abc&order_id=123&" +
+			`purchase_timestamp=1590399311&purchase_payload={"pack":"space","q":"a&b"}`,
+	}
+	r.Signature = signPurchase(t, key, r.SignedData)
+	require.NoError(t, VerifyPurchase(pub, r))
+
+	forged := r
+	forged.SignedData = strings.Replace(r.SignedData, "order_id=123", "order_id=124", 1)
+	require.Error(t, VerifyPurchase(pub, forged))
+
+	// valid signed data of another purchase is replayed
+	for _, f := range []func(r *Request){
+		func(r *Request) { r.PurchaseRequestID = "other" },
+		func(r *Request) { r.PurchaseToken = "other" },
+		func(r *Request) { r.OrderID = "124" },
+		func(r *Request) { r.PurchasePayload = json.RawMessage(`{"pack": "all"}`) },
+		func(r *Request) { r.PurchasePayload = nil },
+	} {
+		forged = r
+		f(&forged)
+		require.Error(t, VerifyPurchase(pub, forged))
+	}
+
+	forged = r
+	forged.SignedData = "purchase_token=abc&order_id=123"
+	forged.Signature = signPurchase(t, key, forged.SignedData)
+	require.Error(t, VerifyPurchase(pub, forged))
+
+	forged = r
+	forged.Signature = "not base64!"
+	require.Error(t, VerifyPurchase(pub, forged))
+
+	forged = r
+	forged.Type = RequestTypeSimpleUtterance
+	require.Error(t, VerifyPurchase(pub, forged))
+
+	_, err = ParsePurchasePublicKey([]byte("not a key"))
+	require.Error(t, err)
+}
+
+func TestPurchaseHandler(t *testing.T) {
+	key, pubPEM := newPurchaseKey(t)
+	pub, err := ParsePurchasePublicKey(pubPEM)
+	require.NoError(t, err)
+
+	cli := New(true, true)
+	cli.SetLogger(func(err error) {})
+	cli.SetPurchaseHandler(func(i InputData) (OutputData, error) {
+		var p map[string]string
+		if err := i.Request.DecodePurchasePayload(&p); err != nil {
+			return OutputData{}, err
+		}
+		return NewOutput(i, NewResponse("Спасибо за покупку пакета "+p["pack"], "", false)), nil
+	}, pub)
+	h := cli.CreateHandler(func(i InputData) (OutputData, error) {
+		return NewOutput(i, NewResponse("default", "", false)), nil
+	})
+
+	signedData := `purchase_request_id=d432de19be8347d09f656d9fe966e1b4&purchase_token=abc&order_id=123&purchase_timestamp=1590399311&purchase_payload={"pack":"space"}`
+	body := `{"request": {"type": "Purchase.Confirmation", "purchase_request_id": "d432de19be8347d09f656d9fe966e1b4",
+		"purchase_token": "abc", "order_id": "123", "purchase_timestamp": 1590399311,
+		"purchase_payload": {"pack": "space"}, "signed_data": %q, "signature": %q}}`
+
+	req, err := http.NewRequest("POST", "/skill", bytes.NewReader([]byte(fmt.Sprintf(body, signedData, signPurchase(t, key, signedData)))))
+	require.NoError(t, err)
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Contains(t, rr.Body.String(), "Спасибо за покупку пакета space")
+
+	req, err = http.NewRequest("POST", "/skill", bytes.NewReader([]byte(fmt.Sprintf(body, signedData, signPurchase(t, key, "other")))))
+	require.NoError(t, err)
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusForbidden, rr.Code)
+}
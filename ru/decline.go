@@ -0,0 +1,335 @@
+package ru
+
+import (
+	"strings"
+	"unicode"
+)
+
+var (
+	// consonant ending nouns: "Иван", "Новосибирск"
+	consonantAnimate   = endings{"", "а", "у", "а", "ом", "е"}
+	consonantInanimate = endings{"", "а", "у", "", "ом", "е"}
+	// "а" ending nouns: "Анна", "Москва"
+	aEndings = endings{"а", "ы", "е", "у", "ой", "е"}
+	// "я" ending nouns: "Ольга", "Илья"
+	yaEndings = endings{"я", "и", "е", "ю", "ей", "е"}
+	// "ия" ending nouns: "Мария", "Россия"
+	iyaEndings = endings{"ия", "ии", "ии", "ию", "ией", "ии"}
+	// "ий" ending names: "Юрий", "Дмитрий"
+	iyEndings = endings{"ий", "ия", "ию", "ия", "ием", "ии"}
+	// masculine "й" and "ь" ending nouns: "Андрей", "Игорь", "Ярославль"
+	yAnimate      = endings{"й", "я", "ю", "я", "ем", "е"}
+	softAnimate   = endings{"ь", "я", "ю", "я", "ем", "е"}
+	softMasculine = endings{"ь", "я", "ю", "ь", "ем", "е"}
+	// feminine "ь" ending nouns: "Любовь", "Казань"
+	softFeminine = endings{"ь", "и", "и", "ь", "ью", "и"}
+	// neuter "о" ending place names: "Иваново"
+	oEndings = endings{"о", "а", "у", "о", "ом", "е"}
+
+	// "ов", "ин" ending last names: "Иванов", "Пушкин"
+	possessiveMasculine = endings{"", "а", "у", "а", "ым", "е"}
+	possessiveFeminine  = endings{"а", "ой", "ой", "у", "ой", "ой"}
+)
+
+// adjective returns endings of adjective in masculine, feminine or neuter gender
+// by its nominative ending: "ий", "ый", "ой", "ая", "яя", "ое" or "ее"
+func adjective(word string, animate bool) (endings, int, bool) {
+	var e endings
+	switch {
+	case hasSuffix(word, "ний", "жий", "ший", "чий", "щий") && !hasSuffix(word, "ский", "цкий"):
+		e = endings{"ий", "его", "ему", "ий", "им", "ем"}
+	case hasSuffix(word, "ий"):
+		e = endings{"ий", "ого", "ому", "ий", "им", "ом"}
+	case hasSuffix(word, "ый"):
+		e = endings{"ый", "ого", "ому", "ый", "ым", "ом"}
+	case hasSuffix(word, "ой"):
+		e = endings{"ой", "ого", "ому", "ой", "ым", "ом"}
+	case hasSuffix(word, "ая"):
+		return endings{"ая", "ой", "ой", "ую", "ой", "ой"}, 2, true
+	case hasSuffix(word, "яя"):
+		return endings{"яя", "ей", "ей", "юю", "ей", "ей"}, 2, true
+	case hasSuffix(word, "ое"):
+		return endings{"ое", "ого", "ому", "ое", "ым", "ом"}, 2, true
+	case hasSuffix(word, "ее"):
+		return endings{"ее", "его", "ему", "ее", "им", "ем"}, 2, true
+	default:
+		return e, 0, false
+	}
+	if animate {
+		e[Accusative] = e[Genitive]
+	}
+	return e, 2, true
+}
+
+// aNoun returns endings of "а" ending noun taking spelling rules into account:
+// "Ольги" instead of "Ольгы", "Сашей" instead of "Сашой"
+func aNoun(word string) endings {
+	e := aEndings
+	prev := beforeLast(word, 1)
+	if strings.ContainsRune("гкхжшчщ", prev) {
+		e[Genitive] = "и"
+	}
+	if strings.ContainsRune("жшчщц", prev) {
+		e[Instrumental] = "ей"
+	}
+	return e
+}
+
+// consonantNoun returns endings of consonant ending noun: "Иваном", but "Абрамовичем"
+func consonantNoun(word string, animate bool) endings {
+	e := consonantInanimate
+	if animate {
+		e = consonantAnimate
+	}
+	if strings.ContainsRune("жшчщц", beforeLast(word, 0)) {
+		e[Instrumental] = "ем"
+	}
+	return e
+}
+
+// fleetingStems are names which lose vowel when declined: "Лев" - "Льва"
+var fleetingStems = map[string]string{
+	"лев":   "льв",
+	"павел": "павл",
+	"пётр":  "петр",
+}
+
+// maleNames are masculine names ending with "а" or "я"
+var maleNames = map[string]bool{
+	"никита": true, "илья": true, "фома": true, "кузьма": true, "лука": true,
+	"савва": true, "данила": true, "гаврила": true, "саша": true, "миша": true,
+	"паша": true, "дима": true, "ваня": true, "вася": true, "петя": true,
+	"коля": true, "толя": true, "серёжа": true, "сережа": true, "лёша": true,
+	"леша": true, "гоша": true, "федя": true, "витя": true, "юра": true,
+}
+
+// femaleSoftNames are feminine names ending with "ь"
+var femaleSoftNames = map[string]bool{
+	"любовь": true, "нинель": true, "адель": true, "руфь": true, "эсфирь": true,
+}
+
+// DeclineFirstName returns first name in case c, gender is used for names
+// which are declined differently for men and women ("Мишель", "Саша")
+func DeclineFirstName(name string, g Gender, c Case) string {
+	return declineParts(name, func(w string) string { return declineFirstName(w, g, c) })
+}
+
+func declineFirstName(name string, g Gender, c Case) string {
+	if c == Nominative {
+		return name
+	}
+	if stem, ok := fleetingStems[name]; ok && g == Masculine {
+		return stem + consonantAnimate[c]
+	}
+
+	switch {
+	case hasSuffix(name, "ия"):
+		return inflect(name, 2, iyaEndings, c)
+	case hasSuffix(name, "а"):
+		return inflect(name, 1, aNoun(name), c)
+	case hasSuffix(name, "я"):
+		return inflect(name, 1, yaEndings, c)
+	case g == Feminine && hasSuffix(name, "ь"):
+		return inflect(name, 1, softFeminine, c)
+	case g == Feminine:
+		return name
+	case hasSuffix(name, "ий"):
+		return inflect(name, 2, iyEndings, c)
+	case hasSuffix(name, "й"):
+		return inflect(name, 1, yAnimate, c)
+	case hasSuffix(name, "ь"):
+		return inflect(name, 1, softAnimate, c)
+	case !isVowel(beforeLast(name, 0)):
+		return inflect(name, 0, consonantNoun(name, true), c)
+	}
+	return name
+}
+
+// DeclinePatronymic returns patronymic in case c
+func DeclinePatronymic(name string, g Gender, c Case) string {
+	if c == Nominative {
+		return name
+	}
+	switch {
+	case hasSuffix(name, "ич"):
+		return inflect(name, 0, consonantNoun(name, true), c)
+	case hasSuffix(name, "на"):
+		return inflect(name, 1, aEndings, c)
+	}
+	return declineFirstName(name, g, c)
+}
+
+// DeclineLastName returns last name in case c. Names of non-russian origin
+// ending with vowels ("Шевченко", "Дюма") and "ых", "их" ending names ("Черных")
+// are not declined, as well as consonant ending last names of women.
+func DeclineLastName(name string, g Gender, c Case) string {
+	return declineParts(name, func(w string) string { return declineLastName(w, g, c) })
+}
+
+func declineLastName(name string, g Gender, c Case) string {
+	if c == Nominative || hasSuffix(name, "ых", "их") {
+		return name
+	}
+
+	if g == Feminine {
+		switch {
+		case hasSuffix(name, "ова", "ева", "ёва", "ина", "ына"):
+			return inflect(name, 1, possessiveFeminine, c)
+		case hasSuffix(name, "ая", "яя"):
+			e, cut, _ := adjective(name, true)
+			return inflect(name, cut, e, c)
+		case hasSuffix(name, "а"):
+			return inflect(name, 1, aNoun(name), c)
+		case hasSuffix(name, "я"):
+			return inflect(name, 1, yaEndings, c)
+		}
+		return name
+	}
+
+	switch {
+	case hasSuffix(name, "ов", "ев", "ёв", "ин", "ын"):
+		return inflect(name, 0, possessiveMasculine, c)
+	case hasSuffix(name, "ий", "ый", "ой"):
+		e, cut, _ := adjective(name, true)
+		return inflect(name, cut, e, c)
+	case hasSuffix(name, "а"):
+		return inflect(name, 1, aNoun(name), c)
+	case hasSuffix(name, "я"):
+		return inflect(name, 1, yaEndings, c)
+	case hasSuffix(name, "й"):
+		return inflect(name, 1, yAnimate, c)
+	case hasSuffix(name, "ь"):
+		return inflect(name, 1, softAnimate, c)
+	case !isVowel(beforeLast(name, 0)):
+		return inflect(name, 0, consonantNoun(name, true), c)
+	}
+	return name
+}
+
+// GuessGender guesses gender of person by patronymic, last name or first name
+func GuessGender(first, patronymic, last string) Gender {
+	first, patronymic, last = strings.ToLower(first), strings.ToLower(patronymic), strings.ToLower(last)
+	switch {
+	case hasSuffix(patronymic, "ич"):
+		return Masculine
+	case hasSuffix(patronymic, "на"):
+		return Feminine
+	case hasSuffix(last, "ова", "ева", "ёва", "ина", "ына", "ая"):
+		return Feminine
+	case hasSuffix(last, "ов", "ев", "ёв", "ин", "ын", "ий", "ый", "ой"):
+		return Masculine
+	case maleNames[first]:
+		return Masculine
+	case femaleSoftNames[first], hasSuffix(first, "а", "я"):
+		return Feminine
+	}
+	return Masculine
+}
+
+// softFeminineNouns are feminine place names ending with "ь"
+var softFeminineNouns = map[string]bool{
+	"казань": true, "пермь": true, "тверь": true, "рязань": true, "астрахань": true,
+	"тюмень": true, "сызрань": true, "керчь": true, "обь": true, "кемь": true,
+	"русь": true, "сибирь": true,
+}
+
+// placePrefixes are first parts of hyphenated place names which are not declined
+var placePrefixes = map[string]bool{
+	"санкт": true, "усть": true, "соль": true, "спас": true, "пало": true,
+	"лос": true, "сан": true, "нью": true, "ла": true,
+}
+
+// DeclinePlace returns name of city, country or other geographical object in case c:
+// "Москву", "Нижнем Новгороде", "Санкт-Петербурга", "Ростове-на-Дону".
+// Names ending with "и", "ы", "е", "у", "ю" ("Сочи", "Химки") are not declined.
+func DeclinePlace(name string, c Case) string {
+	if c == Nominative {
+		return name
+	}
+	lower := strings.ToLower(name)
+	words := strings.Fields(lower)
+	res := make([]string, len(words))
+	for n, w := range words {
+		res[n] = declinePlaceWord(w, c)
+	}
+	return restoreCase(name, strings.Join(res, " "))
+}
+
+func declinePlaceWord(word string, c Case) string {
+	parts := strings.Split(word, "-")
+	if len(parts) >= 3 && parts[1] == "на" {
+		// "ростов-на-дону"
+		parts[0] = declinePlaceNoun(parts[0], c)
+		return strings.Join(parts, "-")
+	}
+	for n, p := range parts {
+		if n < len(parts)-1 && placePrefixes[p] {
+			continue
+		}
+		parts[n] = declinePlaceNoun(p, c)
+	}
+	return strings.Join(parts, "-")
+}
+
+func declinePlaceNoun(word string, c Case) string {
+	if e, cut, ok := adjective(word, false); ok {
+		return inflect(word, cut, e, c)
+	}
+	switch {
+	case hasSuffix(word, "ия"):
+		return inflect(word, 2, iyaEndings, c)
+	case hasSuffix(word, "а"):
+		return inflect(word, 1, aNoun(word), c)
+	case hasSuffix(word, "я"):
+		return inflect(word, 1, yaEndings, c)
+	case hasSuffix(word, "ь") && softFeminineNouns[word]:
+		return inflect(word, 1, softFeminine, c)
+	case hasSuffix(word, "ь"):
+		return inflect(word, 1, softMasculine, c)
+	case hasSuffix(word, "й"):
+		return inflect(word, 1, endings{"й", "я", "ю", "й", "ем", "е"}, c)
+	case hasSuffix(word, "ово", "ево", "ино", "ыно"):
+		return inflect(word, 1, oEndings, c)
+	case !isVowel(beforeLast(word, 0)):
+		return inflect(word, 0, consonantNoun(word, false), c)
+	}
+	return word
+}
+
+// declineParts declines every part of hyphenated name: "Римский-Корсаков"
+func declineParts(name string, decline func(string) string) string {
+	parts := strings.Split(strings.ToLower(name), "-")
+	for n, p := range parts {
+		parts[n] = decline(p)
+	}
+	return restoreCase(name, strings.Join(parts, "-"))
+}
+
+// restoreCase makes letters of declined name upper case where they were upper case
+// in original name, every word and hyphenated part is handled separately
+func restoreCase(orig, declined string) string {
+	split := func(s string) []string {
+		return strings.FieldsFunc(s, func(r rune) bool { return r == ' ' || r == '-' })
+	}
+	o, d := split(orig), split(declined)
+	if len(o) != len(d) {
+		return declined
+	}
+
+	var b strings.Builder
+	rest := declined
+	for n, part := range d {
+		idx := strings.Index(rest, part)
+		b.WriteString(rest[:idx])
+		src, dst := []rune(o[n]), []rune(part)
+		for i := range dst {
+			if i < len(src) && unicode.IsUpper(src[i]) {
+				dst[i] = unicode.ToUpper(dst[i])
+			}
+		}
+		b.WriteString(string(dst))
+		rest = rest[idx+len(part):]
+	}
+	b.WriteString(rest)
+	return b.String()
+}
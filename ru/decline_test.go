@@ -0,0 +1,78 @@
+package ru
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeclineNames(t *testing.T) {
+	for _, tc := range []struct {
+		first, patronymic, last string
+		g                       Gender
+		c                       Case
+		expected                string
+	}{
+		{"Лев", "Николаевич", "Толстой", Masculine, Genitive, "Льва Николаевича Толстого"},
+		{"Лев", "Николаевич", "Толстой", Masculine, Instrumental, "Львом Николаевичем Толстым"},
+		{"Иван", "Сергеевич", "Иванов", Masculine, Dative, "Ивану Сергеевичу Иванову"},
+		{"Андрей", "Юрьевич", "Достоевский", Masculine, Accusative, "Андрея Юрьевича Достоевского"},
+		{"Юрий", "", "Гагарин", Masculine, Prepositional, "Юрии Гагарине"},
+		{"Игорь", "", "Шевченко", Masculine, Genitive, "Игоря Шевченко"},
+		{"Никита", "", "Черных", Masculine, Instrumental, "Никитой Черных"},
+		{"Анна", "Сергеевна", "Иванова", Feminine, Genitive, "Анны Сергеевны Ивановой"},
+		{"Ольга", "", "Толстая", Feminine, Accusative, "Ольгу Толстую"},
+		{"Мария", "", "Кюри", Feminine, Instrumental, "Марией Кюри"},
+		{"Любовь", "", "Орлова", Feminine, Dative, "Любови Орловой"},
+		{"Ирина", "", "Шевчук", Feminine, Genitive, "Ирины Шевчук"},
+		{"Наташа", "", "", Feminine, Instrumental, "Наташей"},
+	} {
+		var parts []string
+		if tc.first != "" {
+			parts = append(parts, DeclineFirstName(tc.first, tc.g, tc.c))
+		}
+		if tc.patronymic != "" {
+			parts = append(parts, DeclinePatronymic(tc.patronymic, tc.g, tc.c))
+		}
+		if tc.last != "" {
+			parts = append(parts, DeclineLastName(tc.last, tc.g, tc.c))
+		}
+		require.Equal(t, tc.expected, strings.Join(parts, " "))
+	}
+}
+
+func TestGuessGender(t *testing.T) {
+	require.Equal(t, Masculine, GuessGender("", "ильич", ""))
+	require.Equal(t, Feminine, GuessGender("", "", "петрова"))
+	require.Equal(t, Masculine, GuessGender("илья", "", ""))
+	require.Equal(t, Feminine, GuessGender("любовь", "", ""))
+	require.Equal(t, Feminine, GuessGender("анна", "", "шевчук"))
+	require.Equal(t, Masculine, GuessGender("иван", "", ""))
+}
+
+func TestDeclinePlace(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		c        Case
+		expected string
+	}{
+		{"Москва", Accusative, "Москву"},
+		{"Москва", Instrumental, "Москвой"},
+		{"Новосибирск", Prepositional, "Новосибирске"},
+		{"Новосибирск", Accusative, "Новосибирск"},
+		{"Санкт-Петербург", Genitive, "Санкт-Петербурга"},
+		{"Ростов-на-Дону", Prepositional, "Ростове-на-Дону"},
+		{"Нижний Новгород", Genitive, "Нижнего Новгорода"},
+		{"Великий Новгород", Dative, "Великому Новгороду"},
+		{"Казань", Instrumental, "Казанью"},
+		{"Ярославль", Genitive, "Ярославля"},
+		{"Россия", Prepositional, "России"},
+		{"Сочи", Genitive, "Сочи"},
+		{"Иваново", Prepositional, "Иванове"},
+		{"Петропавловск-Камчатский", Genitive, "Петропавловска-Камчатского"},
+		{"Набережная", Accusative, "Набережную"},
+	} {
+		require.Equal(t, tc.expected, DeclinePlace(tc.name, tc.c), tc.name)
+	}
+}
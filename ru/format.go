@@ -0,0 +1,191 @@
+package ru
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/temapavloff/galice"
+	"github.com/temapavloff/galice/i18n"
+)
+
+// FormatFIO returns person name in case c: "Льва Николаевича Толстого".
+// Gender is guessed with GuessGender.
+func FormatFIO(v galice.ValueFIO, c Case) i18n.Phrase {
+	g := GuessGender(v.FirstName, v.PatronymicName, v.LastName)
+	var parts []string
+	if v.FirstName != "" {
+		parts = append(parts, DeclineFirstName(capitalize(v.FirstName), g, c))
+	}
+	if v.PatronymicName != "" {
+		parts = append(parts, DeclinePatronymic(capitalize(v.PatronymicName), g, c))
+	}
+	if v.LastName != "" {
+		parts = append(parts, DeclineLastName(capitalize(v.LastName), g, c))
+	}
+	text := strings.Join(parts, " ")
+	return i18n.Phrase{Text: text, TTS: text}
+}
+
+// streetTypes are words of street names which are not capitalized
+var streetTypes = map[string]bool{
+	"улица": true, "проспект": true, "переулок": true, "бульвар": true, "шоссе": true,
+	"площадь": true, "набережная": true, "проезд": true, "тупик": true, "аллея": true,
+}
+
+// FormatGeo returns address in form "Москву, Льва Толстого, 16". The first part
+// (city, country if city is empty, or airport) is declined in case c, others are
+// in nominative case. TTS says "дом" before house number.
+func FormatGeo(v galice.ValueGeo, c Case) i18n.Phrase {
+	var text, tts []string
+	add := func(t, s string) {
+		if len(text) == 0 {
+			t, s = DeclinePlace(t, c), DeclinePlace(s, c)
+		}
+		text, tts = append(text, t), append(tts, s)
+	}
+
+	if v.City != "" {
+		add(capitalize(v.City), capitalize(v.City))
+	} else if v.Country != "" {
+		add(capitalize(v.Country), capitalize(v.Country))
+	}
+	if v.Street != "" {
+		words := strings.Fields(v.Street)
+		for n, w := range words {
+			if !streetTypes[w] {
+				words[n] = capitalize(w)
+			}
+		}
+		street := strings.Join(words, " ")
+		text, tts = append(text, street), append(tts, street)
+	}
+	if v.HouseNumber != "" {
+		text, tts = append(text, v.HouseNumber), append(tts, "дом "+v.HouseNumber)
+	}
+	if v.Airport != "" {
+		a := "аэропорт " + capitalize(v.Airport)
+		if len(text) == 0 {
+			a = DeclinePlace("аэропорт", c) + " " + capitalize(v.Airport)
+		}
+		text, tts = append(text, a), append(tts, a)
+	}
+	if v.City != "" && v.Country != "" {
+		text, tts = append(text, capitalize(v.Country)), append(tts, capitalize(v.Country))
+	}
+	return i18n.Phrase{Text: strings.Join(text, ", "), TTS: strings.Join(tts, ", ")}
+}
+
+// months are names of months in genitive case
+var months = [...]string{
+	"января", "февраля", "марта", "апреля", "мая", "июня",
+	"июля", "августа", "сентября", "октября", "ноября", "декабря",
+}
+
+// monthsPrepositional are names of months in prepositional case
+var monthsPrepositional = [...]string{
+	"январе", "феврале", "марте", "апреле", "мае", "июне",
+	"июле", "августе", "сентябре", "октябре", "ноябре", "декабре",
+}
+
+// relativeDays are words for nearby days
+var relativeDays = map[int]string{
+	-2: "позавчера",
+	-1: "вчера",
+	0:  "сегодня",
+	1:  "завтра",
+	2:  "послезавтра",
+}
+
+// FormatDateTime describes date and time relatively to now: "завтра в 15:00",
+// "через 2 часа 30 минут", "15 мая", "3 дня назад". Absolute dates within two
+// days from now are also described with words like "завтра".
+func FormatDateTime(v galice.ValueDateTime, now time.Time) i18n.Phrase {
+	var parts []string
+
+	// relative part: "через 1 год 2 месяца", "завтра"
+	type unit struct {
+		n              int
+		relative       bool
+		one, few, many string
+	}
+	units := []unit{
+		{v.Year, v.YearIsRelative && v.HasYear(), "год", "года", "лет"},
+		{v.Month, v.MonthIsRelative && v.HasMonth(), "месяц", "месяца", "месяцев"},
+		{v.Day, v.DayIsRelative && v.HasDay(), "день", "дня", "дней"},
+		{v.Hour, v.HourIsRelative && v.HasHour(), "час", "часа", "часов"},
+		{v.Minute, v.MinuteIsRelative && v.HasMinute(), "минуту", "минуты", "минут"},
+	}
+	onlyDay := v.DayIsRelative && !v.YearIsRelative && !v.MonthIsRelative && !v.HourIsRelative && !v.MinuteIsRelative
+	if word, ok := relativeDays[v.Day]; ok && onlyDay {
+		parts = append(parts, word)
+	} else if v.IsRelative() {
+		var rel []string
+		past := false
+		for _, u := range units {
+			if !u.relative || u.n == 0 {
+				continue
+			}
+			n := u.n
+			if n < 0 {
+				n, past = -n, true
+			}
+			rel = append(rel, plural(n, u.one, u.few, u.many))
+		}
+		switch {
+		case len(rel) == 0:
+			parts = append(parts, "сейчас")
+		case past:
+			parts = append(parts, strings.Join(rel, " ")+" назад")
+		default:
+			parts = append(parts, "через "+strings.Join(rel, " "))
+		}
+	}
+
+	// absolute date: "15 мая 2025 года"
+	if !v.IsRelative() {
+		if date := formatDate(v, now); date != "" {
+			parts = append(parts, date)
+		}
+	}
+
+	// absolute time: "в 15:00"
+	if v.HasHour() && !v.HourIsRelative && !v.MinuteIsRelative {
+		parts = append(parts, fmt.Sprintf("в %d:%02d", v.Hour, v.Minute))
+	}
+
+	text := strings.Join(parts, " ")
+	return i18n.Phrase{Text: text, TTS: text}
+}
+
+// formatDate formats absolute date of ValueDateTime
+func formatDate(v galice.ValueDateTime, now time.Time) string {
+	year := now.Year()
+	if v.HasYear() {
+		year = v.Year
+	}
+	validMonth := v.Month >= 1 && v.Month <= 12
+
+	switch {
+	case v.HasDay() && v.HasMonth() && validMonth:
+		// UTC dates are used, so days are always 24 hours long
+		date := time.Date(year, time.Month(v.Month), v.Day, 0, 0, 0, 0, time.UTC)
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		if word, ok := relativeDays[int(date.Sub(today)/(24*time.Hour))]; ok {
+			return word
+		}
+		if year != now.Year() {
+			return fmt.Sprintf("%d %v %d года", v.Day, months[v.Month-1], year)
+		}
+		return fmt.Sprintf("%d %v", v.Day, months[v.Month-1])
+	case v.HasDay():
+		return fmt.Sprintf("%d числа", v.Day)
+	case v.HasMonth() && validMonth && v.HasYear():
+		return fmt.Sprintf("в %v %d года", monthsPrepositional[v.Month-1], year)
+	case v.HasMonth() && validMonth:
+		return "в " + monthsPrepositional[v.Month-1]
+	case v.HasYear():
+		return fmt.Sprintf("в %d году", year)
+	}
+	return ""
+}
@@ -0,0 +1,56 @@
+package ru
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/temapavloff/galice"
+	"github.com/temapavloff/galice/i18n"
+)
+
+func TestFormatFIO(t *testing.T) {
+	p := FormatFIO(galice.ValueFIO{FirstName: "лев", LastName: "толстой"}, Accusative)
+	require.Equal(t, i18n.Phrase{Text: "Льва Толстого", TTS: "Льва Толстого"}, p)
+
+	p = FormatFIO(galice.ValueFIO{FirstName: "анна", PatronymicName: "андреевна", LastName: "ахматова"}, Instrumental)
+	require.Equal(t, "Анной Андреевной Ахматовой", p.Text)
+}
+
+func TestFormatGeo(t *testing.T) {
+	p := FormatGeo(galice.ValueGeo{City: "москва", Street: "улица льва толстого", HouseNumber: "16"}, Accusative)
+	require.Equal(t, "Москву, улица Льва Толстого, 16", p.Text)
+	require.Equal(t, "Москву, улица Льва Толстого, дом 16", p.TTS)
+
+	p = FormatGeo(galice.ValueGeo{City: "ростов-на-дону", Country: "россия"}, Prepositional)
+	require.Equal(t, "Ростове-на-Дону, Россия", p.Text)
+
+	p = FormatGeo(galice.ValueGeo{Airport: "шереметьево"}, Prepositional)
+	require.Equal(t, "аэропорте Шереметьево", p.Text)
+}
+
+func TestFormatDateTime(t *testing.T) {
+	now := time.Date(2026, time.May, 20, 12, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		value    string
+		expected string
+	}{
+		{`{"day": 1, "day_is_relative": true, "hour": 15, "minute": 0}`, "завтра в 15:00"},
+		{`{"day": 0, "day_is_relative": true}`, "сегодня"},
+		{`{"day": 5, "day_is_relative": true}`, "через 5 дней"},
+		{`{"day": -3, "day_is_relative": true}`, "3 дня назад"},
+		{`{"hour": 2, "hour_is_relative": true}`, "через 2 часа"},
+		{`{"hour": 1, "hour_is_relative": true, "minute": 21, "minute_is_relative": true}`, "через 1 час 21 минуту"},
+		{`{"month": 5, "day": 21}`, "завтра"},
+		{`{"month": 9, "day": 15, "hour": 0, "minute": 30}`, "15 сентября в 0:30"},
+		{`{"year": 1982, "month": 9, "day": 15}`, "15 сентября 1982 года"},
+		{`{"month": 12}`, "в декабре"},
+		{`{"year": 2030}`, "в 2030 году"},
+		{`{"hour": 21, "minute": 5}`, "в 21:05"},
+	} {
+		var v galice.ValueDateTime
+		require.NoError(t, json.Unmarshal([]byte(tc.value), &v))
+		require.Equal(t, tc.expected, FormatDateTime(v, now).Text, tc.value)
+	}
+}
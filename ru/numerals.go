@@ -0,0 +1,317 @@
+package ru
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"github.com/temapavloff/galice/i18n"
+)
+
+var (
+	unitsMasculine = [...]string{"ноль", "один", "два", "три", "четыре", "пять", "шесть", "семь", "восемь", "девять"}
+	teens          = [...]string{"десять", "одиннадцать", "двенадцать", "тринадцать", "четырнадцать", "пятнадцать", "шестнадцать", "семнадцать", "восемнадцать", "девятнадцать"}
+	tens           = [...]string{"", "", "двадцать", "тридцать", "сорок", "пятьдесят", "шестьдесят", "семьдесят", "восемьдесят", "девяносто"}
+	hundreds       = [...]string{"", "сто", "двести", "триста", "четыреста", "пятьсот", "шестьсот", "семьсот", "восемьсот", "девятьсот"}
+)
+
+// scale is a name of thousands group: "тысяча", "миллион", etc.
+type scale struct {
+	gender         Gender
+	one, few, many string
+}
+
+var scales = [...]scale{
+	{},
+	{Feminine, "тысяча", "тысячи", "тысяч"},
+	{Masculine, "миллион", "миллиона", "миллионов"},
+	{Masculine, "миллиард", "миллиарда", "миллиардов"},
+	{Masculine, "триллион", "триллиона", "триллионов"},
+	{Masculine, "квадриллион", "квадриллиона", "квадриллионов"},
+	{Masculine, "квинтиллион", "квинтиллиона", "квинтиллионов"},
+}
+
+// unit returns number from 0 to 9 in gender g
+func unit(n uint64, g Gender) string {
+	switch {
+	case n == 1 && g == Feminine:
+		return "одна"
+	case n == 1 && g == Neuter:
+		return "одно"
+	case n == 2 && g == Feminine:
+		return "две"
+	}
+	return unitsMasculine[n]
+}
+
+// triple returns words of number from 1 to 999
+func triple(n uint64, g Gender) []string {
+	var words []string
+	if h := n / 100; h > 0 {
+		words = append(words, hundreds[h])
+	}
+	switch t := n % 100; {
+	case t >= 10 && t < 20:
+		words = append(words, teens[t-10])
+	case t > 0:
+		if t/10 > 0 {
+			words = append(words, tens[t/10])
+		}
+		if t%10 > 0 {
+			words = append(words, unit(t%10, g))
+		}
+	}
+	return words
+}
+
+// Cardinal spells integer in nominative case, g is a gender of counted noun:
+// Cardinal(1500, Masculine) is "одна тысяча пятьсот", Cardinal(22, Feminine) is "двадцать две"
+func Cardinal(n int64, g Gender) string {
+	if n == 0 {
+		return unitsMasculine[0]
+	}
+	var words []string
+	if n < 0 {
+		words = append(words, "минус")
+	}
+	return strings.Join(append(words, cardinal(abs(n), g)...), " ")
+}
+
+func cardinal(n uint64, g Gender) []string {
+	var groups []uint64
+	for ; n > 0; n /= 1000 {
+		groups = append(groups, n%1000)
+	}
+
+	var words []string
+	for i := len(groups) - 1; i >= 0; i-- {
+		if groups[i] == 0 {
+			continue
+		}
+		if i == 0 {
+			words = append(words, triple(groups[i], g)...)
+			continue
+		}
+		s := scales[i]
+		words = append(words, triple(groups[i], s.gender)...)
+		words = append(words, pluralWord(groups[i], s.one, s.few, s.many))
+	}
+	return words
+}
+
+// Quantity spells integer with noun agreed in number and gender:
+// Quantity(21, Feminine, "минута", "минуты", "минут") is "двадцать одна минута"
+func Quantity(n int64, g Gender, one, few, many string) string {
+	return Cardinal(n, g) + " " + pluralWord(abs(n), one, few, many)
+}
+
+// ordinalStems are stems of ordinal numerals, adjective endings are added to them
+var ordinalStems = map[uint64]string{
+	0: "нулев", 1: "перв", 2: "втор", 3: "трет", 4: "четвёрт", 5: "пят", 6: "шест", 7: "седьм", 8: "восьм", 9: "девят",
+	10: "десят", 11: "одиннадцат", 12: "двенадцат", 13: "тринадцат", 14: "четырнадцат", 15: "пятнадцат",
+	16: "шестнадцат", 17: "семнадцат", 18: "восемнадцат", 19: "девятнадцат",
+	20: "двадцат", 30: "тридцат", 40: "сороков", 50: "пятидесят", 60: "шестидесят",
+	70: "семидесят", 80: "восьмидесят", 90: "девяност",
+	100: "сот", 200: "двухсот", 300: "трёхсот", 400: "четырёхсот", 500: "пятисот",
+	600: "шестисот", 700: "семисот", 800: "восьмисот", 900: "девятисот",
+}
+
+// stressedOrdinals have "ой" ending in masculine nominative case: "второй", "сороковой"
+var stressedOrdinals = map[uint64]bool{0: true, 2: true, 6: true, 7: true, 8: true, 40: true}
+
+// scaleStems are genitive forms of multipliers in compound ordinals: "двухтысячный"
+var scaleStems = [...]string{"", "", "двух", "трёх", "четырёх", "пяти", "шести", "семи", "восьми", "девяти"}
+
+var (
+	ordinalMasculine = endings{"ый", "ого", "ому", "ый", "ым", "ом"}
+	ordinalFeminine  = endings{"ая", "ой", "ой", "ую", "ой", "ой"}
+	ordinalNeuter    = endings{"ое", "ого", "ому", "ое", "ым", "ом"}
+	thirdMasculine   = endings{"ий", "ьего", "ьему", "ий", "ьим", "ьем"}
+	thirdFeminine    = endings{"ья", "ьей", "ьей", "ью", "ьей", "ьей"}
+	thirdNeuter      = endings{"ье", "ьего", "ьему", "ье", "ьим", "ьем"}
+)
+
+// Ordinal spells ordinal numeral in gender g and case c: Ordinal(21, Neuter, Nominative)
+// is "двадцать первое", Ordinal(2026, Masculine, Genitive) is "две тысячи двадцать шестого".
+// Accusative case of masculine numerals is the same as nominative ("первый раз").
+func Ordinal(n int64, g Gender, c Case) string {
+	var words []string
+	if n < 0 {
+		words = append(words, "минус")
+	}
+	u := abs(n)
+
+	// the last non-zero group is ordinal, the previous ones are cardinal
+	var last uint64
+	var scaleIdx int
+	for rest, i := u, 0; ; rest, i = rest/1000, i+1 {
+		if rest%1000 != 0 || rest < 1000 {
+			last, scaleIdx = rest%1000, i
+			break
+		}
+	}
+	var p uint64 = 1
+	for i := 0; i < scaleIdx; i++ {
+		p *= 1000
+	}
+	if prefix := u - u%(p*1000); prefix > 0 {
+		words = append(words, cardinal(prefix, Masculine)...)
+	}
+
+	if scaleIdx > 0 && last > 0 {
+		// round thousands: "тысячный", "двухтысячный", but "двадцать тысячный" for larger multipliers
+		s := strings.TrimSuffix(scales[scaleIdx].one, "а")
+		if scaleIdx == 1 {
+			s = "тысячн"
+		} else {
+			s += "н"
+		}
+		switch {
+		case last == 1:
+		case last < 10:
+			s = scaleStems[last] + s
+		default:
+			words = append(words, cardinal(last, Masculine)...)
+		}
+		return strings.Join(append(words, s+ordinalEnding(1, g, c)), " ")
+	}
+
+	var stem uint64
+	switch t := last % 100; {
+	case t == 0 && last >= 100:
+		stem = last
+	case t < 20:
+		if h := last / 100; h > 0 {
+			words = append(words, hundreds[h])
+		}
+		stem = t
+	case t%10 == 0:
+		if h := last / 100; h > 0 {
+			words = append(words, hundreds[h])
+		}
+		stem = t
+	default:
+		if h := last / 100; h > 0 {
+			words = append(words, hundreds[h])
+		}
+		words = append(words, tens[t/10])
+		stem = t % 10
+	}
+	return strings.Join(append(words, ordinalStems[stem]+ordinalEnding(stem, g, c)), " ")
+}
+
+func ordinalEnding(stem uint64, g Gender, c Case) string {
+	var e endings
+	switch {
+	case stem == 3 && g == Feminine:
+		e = thirdFeminine
+	case stem == 3 && g == Neuter:
+		e = thirdNeuter
+	case stem == 3:
+		e = thirdMasculine
+	case g == Feminine:
+		e = ordinalFeminine
+	case g == Neuter:
+		e = ordinalNeuter
+	default:
+		e = ordinalMasculine
+		if stressedOrdinals[stem] {
+			e[Nominative], e[Accusative] = "ой", "ой"
+		}
+	}
+	return e[c]
+}
+
+// Float spells floating point number with up to 3 digits after point:
+// Float(2.5) is "две целых пять десятых". Integers are spelled as Cardinal.
+func Float(f float64) string {
+	neg := f < 0
+	f = math.Abs(f)
+	whole := math.Floor(f)
+	frac := int64(math.Round((f - whole) * 1000))
+	if frac == 1000 {
+		whole, frac = whole+1, 0
+	}
+
+	var words []string
+	if neg && (whole > 0 || frac > 0) {
+		words = append(words, "минус")
+	}
+	if frac == 0 {
+		return strings.Join(append(words, Cardinal(int64(whole), Masculine)), " ")
+	}
+
+	one, many := "тысячная", "тысячных"
+	switch {
+	case frac%100 == 0:
+		frac, one, many = frac/100, "десятая", "десятых"
+	case frac%10 == 0:
+		frac, one, many = frac/10, "сотая", "сотых"
+	}
+	words = append(words, Quantity(int64(whole), Feminine, "целая", "целых", "целых"))
+	words = append(words, Quantity(frac, Feminine, one, many, many))
+	return strings.Join(words, " ")
+}
+
+// Time spells time of day: Time(21, 30) is "двадцать один час тридцать минут",
+// Time(15, 0) is "пятнадцать часов"
+func Time(hour, minute int) string {
+	s := Quantity(int64(hour), Masculine, "час", "часа", "часов")
+	if minute != 0 {
+		s += " " + Quantity(int64(minute), Feminine, "минута", "минуты", "минут")
+	}
+	return s
+}
+
+// Duration spells duration in hours, minutes and seconds: "два часа пятнадцать минут".
+// Seconds are spelled only for durations shorter than an hour, zero duration is "ноль секунд".
+func Duration(d time.Duration) string {
+	if d < 0 {
+		return "минус " + Duration(-d)
+	}
+	h, m, s := int64(d/time.Hour), int64(d%time.Hour/time.Minute), int64(d%time.Minute/time.Second)
+
+	var words []string
+	if h > 0 {
+		words = append(words, Quantity(h, Masculine, "час", "часа", "часов"))
+	}
+	if m > 0 {
+		words = append(words, Quantity(m, Feminine, "минута", "минуты", "минут"))
+	}
+	if h == 0 && (s > 0 || m == 0) {
+		words = append(words, Quantity(s, Feminine, "секунда", "секунды", "секунд"))
+	}
+	return strings.Join(words, " ")
+}
+
+// Rubles spells amount of money: Rubles(1500.5) is "одна тысяча пятьсот рублей пятьдесят копеек"
+func Rubles(amount float64) string {
+	kopecks := int64(math.Round(amount * 100))
+	var words []string
+	if kopecks < 0 {
+		words, kopecks = append(words, "минус"), -kopecks
+	}
+	words = append(words, Quantity(kopecks/100, Masculine, "рубль", "рубля", "рублей"))
+	if k := kopecks % 100; k > 0 {
+		words = append(words, Quantity(k, Feminine, "копейка", "копейки", "копеек"))
+	}
+	return strings.Join(words, " ")
+}
+
+// pluralWord returns noun form for n
+func pluralWord(n uint64, one, few, many string) string {
+	switch i18n.Plural("ru", int64(n%100)) {
+	case i18n.PluralOne:
+		return one
+	case i18n.PluralFew:
+		return few
+	}
+	return many
+}
+
+func abs(n int64) uint64 {
+	if n < 0 {
+		return uint64(-(n + 1)) + 1
+	}
+	return uint64(n)
+}
@@ -0,0 +1,83 @@
+package ru
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCardinal(t *testing.T) {
+	require.Equal(t, "ноль", Cardinal(0, Masculine))
+	require.Equal(t, "одна тысяча пятьсот", Cardinal(1500, Masculine))
+	require.Equal(t, "двадцать две", Cardinal(22, Feminine))
+	require.Equal(t, "одно", Cardinal(1, Neuter))
+	require.Equal(t, "минус сто одиннадцать", Cardinal(-111, Masculine))
+	require.Equal(t, "два миллиона три тысячи один", Cardinal(2003001, Masculine))
+	require.Equal(t, "девять квинтиллионов двести двадцать три квадриллиона триста семьдесят два триллиона тридцать шесть миллиардов восемьсот пятьдесят четыре миллиона семьсот семьдесят пять тысяч восемьсот восемь",
+		Cardinal(-9223372036854775808, Masculine)[len("минус "):])
+	require.Equal(t, "двадцать одна минута", Quantity(21, Feminine, "минута", "минуты", "минут"))
+	require.Equal(t, "одиннадцать минут", Quantity(11, Feminine, "минута", "минуты", "минут"))
+}
+
+func TestOrdinal(t *testing.T) {
+	for _, tc := range []struct {
+		n        int64
+		g        Gender
+		c        Case
+		expected string
+	}{
+		{21, Neuter, Nominative, "двадцать первое"},
+		{2, Masculine, Nominative, "второй"},
+		{3, Feminine, Accusative, "третью"},
+		{3, Masculine, Genitive, "третьего"},
+		{40, Masculine, Nominative, "сороковой"},
+		{100, Feminine, Prepositional, "сотой"},
+		{115, Masculine, Instrumental, "сто пятнадцатым"},
+		{2026, Masculine, Genitive, "две тысячи двадцать шестого"},
+		{1000, Masculine, Nominative, "тысячный"},
+		{2000, Masculine, Prepositional, "двухтысячном"},
+		{0, Masculine, Nominative, "нулевой"},
+	} {
+		require.Equal(t, tc.expected, Ordinal(tc.n, tc.g, tc.c))
+	}
+}
+
+func TestFloatTimeMoney(t *testing.T) {
+	require.Equal(t, "две целых пять десятых", Float(2.5))
+	require.Equal(t, "одна целая двадцать пять сотых", Float(1.25))
+	require.Equal(t, "ноль целых одна тысячная", Float(0.001))
+	require.Equal(t, "минус три", Float(-3))
+
+	require.Equal(t, "двадцать один час тридцать минут", Time(21, 30))
+	require.Equal(t, "пятнадцать часов", Time(15, 0))
+
+	require.Equal(t, "два часа пятнадцать минут", Duration(2*time.Hour+15*time.Minute+10*time.Second))
+	require.Equal(t, "одна минута одна секунда", Duration(61*time.Second))
+	require.Equal(t, "ноль секунд", Duration(0))
+
+	require.Equal(t, "одна тысяча пятьсот рублей", Rubles(1500))
+	require.Equal(t, "двадцать один рубль две копейки", Rubles(21.02))
+}
+
+func TestSpellNumbers(t *testing.T) {
+	require.Equal(t, "Стоимость одна тысяча пятьсот рублей, встречаемся двадцать первое мая в двадцать один час тридцать минут",
+		SpellNumbers("Стоимость 1 500 ₽, встречаемся 21 мая в 21:30"))
+	require.Equal(t, "девяносто девять рублей девяносто копеек за две целых пять десятых кг", SpellNumbers("99,9 руб. за 2.5 кг"))
+	require.Equal(t, "пять рубашек", SpellNumbers("5 рубашек"))
+	require.Equal(t, "сто рублей", SpellNumbers("100 руб"))
+	require.Equal(t, "Звоните восемь восемьсот пятьсот пятьдесят пять тридцать пять тридцать пять",
+		SpellNumbers("Звоните 8 800 555 35 35"))
+	require.Equal(t, "минус пять градусов, до минус две целых пять десятых", SpellNumbers("-5 градусов, до −2,5"))
+	require.Equal(t, "минус сто рублей", SpellNumbers("-100 ₽"))
+	require.Equal(t, "Ту-сто пятьдесят четыре, пять-шесть дней", SpellNumbers("Ту-154, 5-6 дней"))
+	require.Equal(t, "версия 1.2.3, адрес 192.168.0.1, версия одна целая две десятых",
+		SpellNumbers("версия 1.2.3, адрес 192.168.0.1, версия 1.2"))
+}
+
+func TestSpellNumbersMarkup(t *testing.T) {
+	require.Equal(t, `<speaker audio="alice-sounds-game-win-1.opus"> Вы набрали сто очков`,
+		SpellNumbers(`<speaker audio="alice-sounds-game-win-1.opus"> Вы набрали 100 очков`))
+	require.Equal(t, "Через пять секунд sil <[500]> начинаем sil <[1000]>",
+		SpellNumbers("Через 5 секунд sil <[500]> начинаем sil <[1000]>"))
+}
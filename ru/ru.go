@@ -0,0 +1,112 @@
+// Package ru renders values of Alice API named entities in russian speech:
+// names and cities are declined by grammatical case, dates and times are
+// described relatively to the current moment ("завтра в 15:00", "через 2 часа").
+package ru
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/temapavloff/galice/i18n"
+)
+
+// Case is a grammatical case of russian noun
+type Case uint8
+
+const (
+	// Nominative is a case answering "кто? что?" ("Москва")
+	Nominative = Case(iota)
+	// Genitive is a case answering "кого? чего?" ("Москвы")
+	Genitive
+	// Dative is a case answering "кому? чему?" ("Москве")
+	Dative
+	// Accusative is a case answering "кого? что?" ("Москву")
+	Accusative
+	// Instrumental is a case answering "кем? чем?" ("Москвой")
+	Instrumental
+	// Prepositional is a case answering "о ком? о чём?" ("Москве")
+	Prepositional
+)
+
+// Gender is a grammatical gender of russian noun
+type Gender uint8
+
+const (
+	// Masculine is a gender of "он"
+	Masculine = Gender(iota)
+	// Feminine is a gender of "она"
+	Feminine
+	// Neuter is a gender of "оно"
+	Neuter
+)
+
+// endings are word endings for every case, index is a Case value
+type endings [6]string
+
+// inflect replaces the last cut letters of word with ending of case c
+func inflect(word string, cut int, e endings, c Case) string {
+	r := []rune(word)
+	if cut > len(r) {
+		cut = len(r)
+	}
+	return string(r[:len(r)-cut]) + e[c]
+}
+
+// hasSuffix checks if word ends with any of suffixes
+func hasSuffix(word string, suffixes ...string) bool {
+	for _, s := range suffixes {
+		if strings.HasSuffix(word, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// beforeLast returns letter before the last n letters of word
+func beforeLast(word string, n int) rune {
+	r := []rune(word)
+	if len(r) <= n {
+		return 0
+	}
+	return r[len(r)-n-1]
+}
+
+// isVowel checks if r is a russian vowel
+func isVowel(r rune) bool {
+	return strings.ContainsRune("аеёиоуыэюя", r)
+}
+
+// particles are parts of hyphenated names which are not capitalized: "Ростов-на-Дону"
+var particles = map[string]bool{"на": true, "де": true, "дель": true, "ле": true}
+
+// capitalize makes the first letter of every word and every hyphenated part upper case
+func capitalize(s string) string {
+	words := strings.Fields(s)
+	for n, w := range words {
+		parts := strings.Split(w, "-")
+		for i, p := range parts {
+			if i > 0 && particles[strings.ToLower(p)] {
+				continue
+			}
+			r := []rune(p)
+			if len(r) > 0 {
+				r[0] = unicode.ToUpper(r[0])
+			}
+			parts[i] = string(r)
+		}
+		words[n] = strings.Join(parts, "-")
+	}
+	return strings.Join(words, " ")
+}
+
+// plural returns n with noun in proper plural form: "1 день", "2 дня", "5 дней"
+func plural(n int, one, few, many string) string {
+	switch i18n.Plural("ru", int64(n)) {
+	case i18n.PluralOne:
+		return strconv.Itoa(n) + " " + one
+	case i18n.PluralFew:
+		return strconv.Itoa(n) + " " + few
+	}
+	return strconv.Itoa(n) + " " + many
+}
@@ -0,0 +1,131 @@
+package ru
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	monthNames   = strings.Join(months[:], "|")
+	dateRegexp   = regexp.MustCompile(`\b(\d{1,2})\s+(` + monthNames + `)`)
+	timeRegexp   = regexp.MustCompile(`\b(\d{1,2}):(\d{2})\b`)
+	amount       = `(\d{1,3}(?:[ \x{00A0}]\d{3})+|\d+)(?:[.,](\d{1,2}))?`
+	moneyRegexp  = regexp.MustCompile(`([-−])?\b` + amount + `\s*(?:₽|руб\.|р\.|руб([^\p{L}]|$))`)
+	numberRegexp = regexp.MustCompile(`([-−])?\b(\d+)(?:[.,](\d+))?\b`)
+	// keepRegexp matches text which is not spelled: TTS tags and pauses (<speaker audio="...">,
+	// sil <[500]>) and dotted versions or addresses ("1.2.3", "192.168.0.1")
+	keepRegexp = regexp.MustCompile(`<[^>]*>|\d+(?:\.\d+){2,}`)
+)
+
+// SpellNumbers replaces numbers in text with words, so TTS reads them properly:
+// dates ("21 мая" is "двадцать первое мая"), times ("21:30"), amounts of money
+// ("1 500 ₽", "99 руб.") and other numbers, which are spelled in masculine gender.
+// Minus sign before number is spelled as "минус" ("-5" is "минус пять").
+// Spaces are treated as thousands separators only in amounts of money, so groups
+// of phone numbers are spelled one by one: "8 800 555 35 35" is "восемь восемьсот
+// пятьсот пятьдесят пять тридцать пять тридцать пять", not millions.
+// TTS tags and pauses (<speaker ...>, sil <[500]>) and dotted versions ("1.2.3") are left intact.
+// It can be used as TTS filter of galice.ResponseBuilder.
+func SpellNumbers(s string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range keepRegexp.FindAllStringIndex(s, -1) {
+		b.WriteString(spellNumbers(s[last:loc[0]]))
+		b.WriteString(s[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	b.WriteString(spellNumbers(s[last:]))
+	return b.String()
+}
+
+func spellNumbers(s string) string {
+	s = dateRegexp.ReplaceAllStringFunc(s, func(m string) string {
+		sub := dateRegexp.FindStringSubmatch(m)
+		day, _ := strconv.ParseInt(sub[1], 10, 64)
+		if day < 1 || day > 31 {
+			return m
+		}
+		return Ordinal(day, Neuter, Nominative) + " " + sub[2]
+	})
+
+	s = timeRegexp.ReplaceAllStringFunc(s, func(m string) string {
+		sub := timeRegexp.FindStringSubmatch(m)
+		h, _ := strconv.Atoi(sub[1])
+		min, _ := strconv.Atoi(sub[2])
+		if h > 23 || min > 59 {
+			return m
+		}
+		return Time(h, min)
+	})
+
+	s = replaceAll(moneyRegexp, s, func(sub []string, prev rune) string {
+		rub, err := parseInt(sub[2])
+		if err != nil {
+			return sub[0]
+		}
+		var kop int64
+		if sub[3] != "" {
+			kop, _ = strconv.ParseInt(sub[3], 10, 64)
+			if len(sub[3]) == 1 {
+				kop *= 10
+			}
+		}
+		return sign(sub[1], prev) + Rubles(float64(rub)+float64(kop)/100) + sub[4]
+	})
+
+	return replaceAll(numberRegexp, s, func(sub []string, prev rune) string {
+		n, err := parseInt(sub[2])
+		if err != nil {
+			return sub[0]
+		}
+		if sub[3] == "" {
+			return sign(sub[1], prev) + Cardinal(n, Masculine)
+		}
+		f, err := strconv.ParseFloat(strconv.FormatInt(n, 10)+"."+sub[3], 64)
+		if err != nil {
+			return sub[0]
+		}
+		return sign(sub[1], prev) + Float(f)
+	})
+}
+
+// replaceAll is like regexp.ReplaceAllStringFunc, but fn gets submatches
+// and rune preceding the match
+func replaceAll(re *regexp.Regexp, s string, fn func(sub []string, prev rune) string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range re.FindAllStringSubmatchIndex(s, -1) {
+		sub := make([]string, len(loc)/2)
+		for n := range sub {
+			if loc[2*n] >= 0 {
+				sub[n] = s[loc[2*n]:loc[2*n+1]]
+			}
+		}
+		prev, _ := utf8.DecodeLastRuneInString(s[:loc[0]])
+		b.WriteString(s[last:loc[0]])
+		b.WriteString(fn(sub, prev))
+		last = loc[1]
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// sign spells minus before number, minus after letter or digit is a hyphen: "Ту-154", "1-5"
+func sign(minus string, prev rune) string {
+	switch {
+	case minus == "":
+		return ""
+	case unicode.IsLetter(prev) || unicode.IsDigit(prev):
+		return minus
+	}
+	return "минус "
+}
+
+// parseInt parses integer with spaces between thousands: "1 500"
+func parseInt(s string) (int64, error) {
+	s = strings.NewReplacer(" ", "", " ", "").Replace(s)
+	return strconv.ParseInt(s, 10, 64)
+}
@@ -0,0 +1,309 @@
+package galice
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/temapavloff/galice/internal/plural"
+)
+
+// DefaultAbbreviations are abbreviations expanded by Sanitizer. Expansions with
+// forms separated by "|" ("рубль|рубля|рублей") are agreed with preceding number.
+// "г." is expanded only before capitalized word not preceded by number ("г. Москва"),
+// as it may also mean "год" or "грамм" ("1998 г.", "200 г. муки").
+var DefaultAbbreviations = map[string]string{
+	"г.":    "город",
+	"т.е.":  "то есть",
+	"т.к.":  "так как",
+	"т.д.":  "так далее",
+	"т.п.":  "тому подобное",
+	"др.":   "другие",
+	"руб.":  "рубль|рубля|рублей",
+	"коп.":  "копейка|копейки|копеек",
+	"тыс.":  "тысяча|тысячи|тысяч",
+	"млн":   "миллион|миллиона|миллионов",
+	"млрд":  "миллиард|миллиарда|миллиардов",
+	"мин.":  "минута|минуты|минут",
+	"сек.":  "секунда|секунды|секунд",
+	"ул.":   "улица",
+	"пр-т":  "проспект",
+	"кв.":   "квартира",
+	"напр.": "например",
+}
+
+var (
+	urlRegexp        = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s]+`)
+	cityAbbrRegexp   = regexp.MustCompile(`г\.\s*\p{Lu}`)
+	starRegexp       = regexp.MustCompile(`\*+`)
+	markupReplacer   = strings.NewReplacer("_", " ", "#", "", "`", "", "~", "", "•", "", "▪", "")
+	bulletRegexp     = regexp.MustCompile(`^[-–—]\s+`)
+	spaceRegexp      = regexp.MustCompile(`[ \t\x{00A0}]+`)
+	punctSpaceRegexp = regexp.MustCompile(` +([.,!?;:…])`)
+)
+
+// Sanitizer prepares response text for TTS: strips emoji and markup symbols,
+// drops or spells URLs, expands abbreviations and collapses whitespace.
+// Sanitizer is used when TTS of response is derived from text, see SetDefaultSanitizer.
+type Sanitizer struct {
+	spellURLs     bool
+	abbreviations map[string]string
+	abbrRegexp    *regexp.Regexp
+	rules         []TTSFilter
+}
+
+// NewSanitizer creates new Sanitizer with DefaultAbbreviations, URLs are dropped
+func NewSanitizer() *Sanitizer {
+	s := &Sanitizer{abbreviations: map[string]string{}}
+	for abbr, exp := range DefaultAbbreviations {
+		s.abbreviations[abbr] = exp
+	}
+	s.compile()
+	return s
+}
+
+// SetSpellURLs makes Sanitizer replace URLs with their host names ("example точка ru")
+// instead of dropping them
+func (s *Sanitizer) SetSpellURLs(spell bool) {
+	s.spellURLs = spell
+}
+
+// AddAbbreviation adds abbreviation (e.g. "см.") and its expansion (e.g. "смотри").
+// Forms of expansion separated by "|" are agreed with preceding number: "сантиметр|сантиметра|сантиметров".
+// Empty expansion removes abbreviation.
+func (s *Sanitizer) AddAbbreviation(abbr, expansion string) {
+	if expansion == "" {
+		delete(s.abbreviations, abbr)
+	} else {
+		s.abbreviations[abbr] = expansion
+	}
+	s.compile()
+}
+
+// AddRule adds custom rule, rules are applied after built-in ones but before whitespace is collapsed
+func (s *Sanitizer) AddRule(rule TTSFilter) {
+	s.rules = append(s.rules, rule)
+}
+
+// Sanitize returns text prepared for TTS
+func (s *Sanitizer) Sanitize(text string) string {
+	text = urlRegexp.ReplaceAllStringFunc(text, s.replaceURL)
+	text = stripEmoji(text)
+	text = stripStars(text)
+	text = markupReplacer.Replace(text)
+	text = s.expand(text)
+	for _, rule := range s.rules {
+		text = rule(text)
+	}
+	return collapseSpace(text)
+}
+
+func (s *Sanitizer) compile() {
+	abbrs := make([]string, 0, len(s.abbreviations))
+	for abbr := range s.abbreviations {
+		if abbr != cityAbbr {
+			abbrs = append(abbrs, abbr)
+		}
+	}
+	// longer abbreviations first, so "т.е." is not matched as "е."
+	sort.Slice(abbrs, func(i, j int) bool {
+		if len(abbrs[i]) != len(abbrs[j]) {
+			return len(abbrs[i]) > len(abbrs[j])
+		}
+		return abbrs[i] < abbrs[j]
+	})
+
+	alts := make([]string, len(abbrs))
+	for n, abbr := range abbrs {
+		// "т.е." also matches "т. е."
+		alts[n] = strings.Replace(regexp.QuoteMeta(abbr), `\.`, `\.\s*`, -1)
+		alts[n] = strings.TrimSuffix(alts[n], `\s*`)
+	}
+	s.abbrRegexp = nil
+	if len(alts) > 0 {
+		s.abbrRegexp = regexp.MustCompile(`^(?i)(?:(\d+(?:[.,]\d+)?)\s*)?(` + strings.Join(alts, "|") + `)`)
+	}
+}
+
+func (s *Sanitizer) expand(text string) string {
+	if exp, ok := s.abbreviations[cityAbbr]; ok {
+		text = expandCity(text, exp)
+	}
+	if s.abbrRegexp == nil {
+		return text
+	}
+
+	var b strings.Builder
+	prev := ' '
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if !unicode.IsLetter(prev) && !unicode.IsDigit(prev) {
+			if sub := s.abbrRegexp.FindStringSubmatchIndex(text[i:]); sub != nil {
+				next, _ := utf8.DecodeRuneInString(text[i+sub[1]:])
+				if !unicode.IsLetter(next) {
+					var num string
+					if sub[2] >= 0 {
+						num = text[i+sub[2] : i+sub[3]]
+					}
+					b.WriteString(s.expansion(num, text[i+sub[4]:i+sub[5]]))
+					i += sub[1]
+					prev = '.'
+					continue
+				}
+			}
+		}
+		b.WriteRune(r)
+		prev = r
+		i += size
+	}
+	return b.String()
+}
+
+// cityAbbr is an abbreviation expanded only before city name, see expandCity
+const cityAbbr = "г."
+
+// expandCity expands "г." before capitalized word ("г. Москва"), unless it follows
+// a number ("в 1998 г. Сейчас") or is a part of word
+func expandCity(text, exp string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range cityAbbrRegexp.FindAllStringIndex(text, -1) {
+		before := text[:loc[0]]
+		prev, _ := utf8.DecodeLastRuneInString(before)
+		token, _ := utf8.DecodeLastRuneInString(strings.TrimRight(before, " \t\u00a0"))
+		if loc[0] > 0 && (unicode.IsLetter(prev) || unicode.IsDigit(prev) || unicode.IsDigit(token)) {
+			continue
+		}
+		next, _ := utf8.DecodeLastRuneInString(text[:loc[1]])
+		b.WriteString(text[last:loc[0]])
+		b.WriteString(exp + " " + string(next))
+		last = loc[1]
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// expansion returns expanded abbreviation agreed with preceding number
+func (s *Sanitizer) expansion(num, abbr string) string {
+	exp := s.lookup(abbr)
+	if forms := strings.Split(exp, "|"); len(forms) == 3 {
+		exp = forms[2]
+		if strings.ContainsAny(num, ".,") {
+			// fractions agree with genitive singular: "1.5 миллиона"
+			exp = forms[1]
+		} else if n, err := strconv.ParseInt(num, 10, 64); err == nil {
+			exp = forms[plural.Russian(n)]
+		}
+	}
+	if num != "" {
+		return num + " " + exp
+	}
+	return exp
+}
+
+// lookup finds expansion of abbreviation matched ignoring case and spaces
+func (s *Sanitizer) lookup(abbr string) string {
+	key := strings.ToLower(strings.Join(strings.Fields(abbr), ""))
+	for a, exp := range s.abbreviations {
+		if strings.ToLower(a) == key {
+			return exp
+		}
+	}
+	return abbr
+}
+
+func (s *Sanitizer) replaceURL(u string) string {
+	trimmed := strings.TrimRight(u, ".,;:!?)")
+	tail := u[len(trimmed):]
+	if !s.spellURLs {
+		return tail
+	}
+	host := strings.TrimPrefix(strings.TrimPrefix(strings.ToLower(trimmed), "https://"), "http://")
+	host = strings.TrimPrefix(host, "www.")
+	if i := strings.IndexAny(host, "/?#:"); i >= 0 {
+		host = host[:i]
+	}
+	return strings.Replace(host, ".", " точка ", -1) + tail
+}
+
+// stripEmoji removes emoji, pictographs and joiners used in emoji sequences
+func stripEmoji(text string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\u200d', r == '\u20e3', r >= '\ufe00' && r <= '\ufe0f': // joiner, keycap, variation selectors
+			return -1
+		case r >= 0x1f000 && r <= 0x1faff, r >= 0x2600 && r <= 0x27bf, r >= 0x2b00 && r <= 0x2bff,
+			r >= 0x2190 && r <= 0x21ff, r >= 0x2300 && r <= 0x23ff, r >= 0xe0020 && r <= 0xe007f:
+			return -1
+		}
+		return r
+	}, text)
+}
+
+// collapseSpace collapses spaces and joins lines, so line breaks become pauses
+func collapseSpace(text string) string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(spaceRegexp.ReplaceAllString(line, " "))
+		line = bulletRegexp.ReplaceAllString(line, "")
+		if line == "" {
+			continue
+		}
+		if n := len(lines); n > 0 {
+			if r, _ := utf8.DecodeLastRuneInString(lines[n-1]); !strings.ContainsRune(".,!?;:…", r) {
+				lines[n-1] += "."
+			}
+		}
+		lines = append(lines, line)
+	}
+	return punctSpaceRegexp.ReplaceAllString(strings.Join(lines, " "), "$1")
+}
+
+// stripStars removes markdown emphasis ("*Маргарита*", "**Пепперони**"), but keeps
+// multiplication sign surrounded by spaces or digits ("5 * 3", "5*3")
+func stripStars(text string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range starRegexp.FindAllStringIndex(text, -1) {
+		prev, _ := utf8.DecodeLastRuneInString(text[:loc[0]])
+		next, _ := utf8.DecodeRuneInString(text[loc[1]:])
+		arithmetic := loc[1]-loc[0] == 1 && (unicode.IsSpace(prev) && unicode.IsSpace(next) ||
+			unicode.IsDigit(prev) && unicode.IsDigit(next))
+		if arithmetic {
+			continue
+		}
+		b.WriteString(text[last:loc[0]])
+		last = loc[1]
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+var (
+	sanitizerMu      sync.RWMutex
+	defaultSanitizer = NewSanitizer()
+)
+
+// SetDefaultSanitizer sets sanitizer used when TTS of response is derived from text
+// by NewResponse, Response.FilterTTS and ResponseBuilder. Passing nil disables sanitizing,
+// so text is used as TTS verbatim.
+func SetDefaultSanitizer(s *Sanitizer) {
+	sanitizerMu.Lock()
+	defer sanitizerMu.Unlock()
+	defaultSanitizer = s
+}
+
+// sanitize prepares text for TTS with default sanitizer
+func sanitize(text string) string {
+	sanitizerMu.RLock()
+	s := defaultSanitizer
+	sanitizerMu.RUnlock()
+	if s == nil {
+		return text
+	}
+	return s.Sanitize(text)
+}
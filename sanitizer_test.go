@@ -0,0 +1,62 @@
+package galice
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanitizer(t *testing.T) {
+	s := NewSanitizer()
+	for _, tc := range []struct {
+		text     string
+		expected string
+	}{
+		{"Привет! 👋🏻 Закажем пиццу? 🍕", "Привет! Закажем пиццу?"},
+		{"*Маргарита* — 500 руб., **Пепперони** — 21 руб.", "Маргарита — 500 рублей, Пепперони — 21 рубль"},
+		{"Меню:\n• Пицца\n• Суши\n\n#акция", "Меню: Пицца. Суши. акция"},
+		{"Подробности на https://example.com/menu?x=1.", "Подробности на."},
+		{"Т.е. доставка в г. Москва, т. к. 2 млн заказов", "то есть доставка в город Москва, так как 2 миллиона заказов"},
+		{"Основан в 1998 г.", "Основан в 1998 г."},
+		{"Добавьте 200 г. муки", "Добавьте 200 г. муки"},
+		{"5 млн руб.", "5 миллионов рублей"},
+		{"Вгруб. гр.", "Вгруб. гр."},
+		{"Температура 25°, №5", "Температура 25°, №5"},
+		{"Основан в 1998 г. Сейчас работаем", "Основан в 1998 г. Сейчас работаем"},
+		{"Офис в г.Казань", "Офис в город Казань"},
+		{"-5 градусов", "-5 градусов"},
+		{"Температура:\n-5\n- завтра\n— послезавтра", "Температура: -5. завтра. послезавтра"},
+		{"Подождите…\nГотово", "Подождите… Готово"},
+		{"5 * 3 = 15, 2*2 = 4", "5 * 3 = 15, 2*2 = 4"},
+		{"1.5 млн руб., 2,5 тыс.", "1.5 миллиона рублей, 2,5 тысячи"},
+	} {
+		require.Equal(t, tc.expected, s.Sanitize(tc.text), tc.text)
+	}
+
+	s.SetSpellURLs(true)
+	require.Equal(t, "Заходите на example точка com!", s.Sanitize("Заходите на https://www.Example.com/menu!"))
+
+	s.AddAbbreviation("см.", "сантиметр|сантиметра|сантиметров")
+	s.AddAbbreviation("руб.", "")
+	s.AddAbbreviation("г.", "")
+	require.Equal(t, "в г. Москва", s.Sanitize("в г. Москва"))
+	s.AddRule(func(text string) string { return strings.Replace(text, "пицца", "пи́цца", -1) })
+	require.Equal(t, "пи́цца 32 сантиметра за 500 руб.", s.Sanitize("пицца 32 см. за 500   руб."))
+}
+
+func TestDefaultSanitizer(t *testing.T) {
+	require.Equal(t, "Привет!", NewResponse("Привет! 👋", "", false).TTS)
+	require.Equal(t, "Привет! 👋", NewResponse("Привет! 👋", "Привет! 👋", false).TTS)
+
+	o, err := NewBuilder(InputData{}).Text("Цена 5 руб.").Build()
+	require.NoError(t, err)
+	require.Equal(t, "Цена 5 рублей", o.Response.TTS)
+	o, err = NewBuilder(InputData{}).Text("Цена 5 руб.").Sanitizer(nil).Build()
+	require.NoError(t, err)
+	require.Equal(t, "Цена 5 руб.", o.Response.TTS)
+
+	SetDefaultSanitizer(nil)
+	defer SetDefaultSanitizer(NewSanitizer())
+	require.Equal(t, "Привет! 👋", NewResponse("Привет! 👋", "", false).TTS)
+}
@@ -0,0 +1,23 @@
+package galice
+
+import "time"
+
+// ShowTypeMorning is a type of show requested for "Alice's morning show"
+const ShowTypeMorning = "MORNING"
+
+// ShowItemMeta describes content provided in response to Show.Pull request
+type ShowItemMeta struct {
+	ContentID       string     `json:"content_id"`                // unique ID of content, the same content is not played twice
+	Title           string     `json:"title,omitempty"`           // content title shown to user
+	TitleTTS        string     `json:"title_tts,omitempty"`       // content title pronounced by Alice
+	PublicationDate time.Time  `json:"publication_date"`          // time of content publication
+	ExpirationDate  *time.Time `json:"expiration_date,omitempty"` // time after which content is not played
+}
+
+// NewShowResponse creates new response for Show.Pull request. Use text and tts variables
+// to set content of the show, they have the same meaning as in NewResponse.
+func NewShowResponse(text, tts string, meta ShowItemMeta) Response {
+	r := NewResponse(text, tts, true)
+	r.ShowItemMeta = &meta
+	return r
+}
@@ -0,0 +1,51 @@
+package galice
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestShowHandler(t *testing.T) {
+	published := time.Date(2020, 12, 3, 10, 35, 0, 0, time.UTC)
+	expires := published.Add(24 * time.Hour)
+
+	cli := New(true, true)
+	cli.SetShowHandler(func(i InputData) (OutputData, error) {
+		require.Equal(t, ShowTypeMorning, i.Request.ShowType)
+		return NewOutput(i, NewShowResponse("Главная новость дня", "", ShowItemMeta{
+			ContentID:       "news-1",
+			Title:           "Новости",
+			TitleTTS:        "Новости",
+			PublicationDate: published,
+			ExpirationDate:  &expires,
+		})), nil
+	})
+	h := cli.CreateHandler(func(i InputData) (OutputData, error) {
+		return NewOutput(i, NewResponse("default", "", false)), nil
+	})
+
+	req, err := http.NewRequest("POST", "/skill", bytes.NewReader([]byte(`{"request": {"type": "Show.Pull", "show_type": "MORNING"}, "version": "1.0"}`)))
+	require.NoError(t, err)
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, `{"version":"1.0","session":{"new":false,"message_id":0,"session_id":"","skill_id":"","user_id":""},`+
+		`"response":{"text":"Главная новость дня","tts":"Главная новость дня","end_session":true,`+
+		`"show_item_meta":{"content_id":"news-1","title":"Новости","title_tts":"Новости",`+
+		`"publication_date":"2020-12-03T10:35:00Z","expiration_date":"2020-12-04T10:35:00Z"}}}
+`, rr.Body.String())
+}
+
+func TestShowItemValidation(t *testing.T) {
+	_, err := NewBuilder(InputData{}).Text("Новости").ShowItem(ShowItemMeta{}).Build()
+	require.EqualError(t, err, "Invalid response: show item content ID is empty; show item publication date is empty")
+
+	o, err := NewBuilder(InputData{}).Text("Новости").ShowItem(ShowItemMeta{ContentID: "1", PublicationDate: time.Now()}).Build()
+	require.NoError(t, err)
+	require.Equal(t, "1", o.Response.ShowItemMeta.ContentID)
+}
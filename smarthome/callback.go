@@ -0,0 +1,144 @@
+package smarthome
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// DefaultCallbackURL is a base URL of Yandex Dialogs callback API
+const DefaultCallbackURL = "https://dialogs.yandex.net/api/v1"
+
+// callbackPayload is a payload of state and discovery callbacks
+type callbackPayload struct {
+	UserID  string   `json:"user_id"`
+	Devices []Device `json:"devices,omitempty"`
+}
+
+// callbackRequest is a body of state and discovery callbacks
+type callbackRequest struct {
+	TS      float64         `json:"ts"`
+	Payload callbackPayload `json:"payload"`
+}
+
+// callbackResponse is a body of callback API response
+type callbackResponse struct {
+	RequestID    string `json:"request_id"`
+	Status       string `json:"status"`
+	ErrorCode    string `json:"error_code"`
+	ErrorMessage string `json:"error_message"`
+}
+
+// CallbackClient notifies Yandex about changes of devices states and lists
+type CallbackClient struct {
+	skillID    string
+	token      string
+	baseURL    string
+	httpClient *http.Client
+	retries    int           // number of retries after failed attempt
+	backoff    time.Duration // delay before the first retry, doubled for every next one
+	now        func() time.Time
+}
+
+// NewCallbackClient creates new CallbackClient for skill. Token is an OAuth token
+// of skill owner issued for Yandex Dialogs API.
+func NewCallbackClient(skillID, token string) *CallbackClient {
+	return &CallbackClient{
+		skillID:    skillID,
+		token:      token,
+		baseURL:    DefaultCallbackURL,
+		httpClient: http.DefaultClient,
+		retries:    3,
+		backoff:    500 * time.Millisecond,
+		now:        time.Now,
+	}
+}
+
+// SetHTTPClient sets HTTP client used for callbacks. If not called http.DefaultClient is used.
+func (c *CallbackClient) SetHTTPClient(client *http.Client) {
+	c.httpClient = client
+}
+
+// SetBaseURL sets base URL of callback API. If not called DefaultCallbackURL is used.
+func (c *CallbackClient) SetBaseURL(URL string) {
+	c.baseURL = URL
+}
+
+// SetRetries sets number of retries of failed callbacks and delay before the first retry,
+// every next delay is twice as long as previous. Callbacks are retried on network errors,
+// 5xx and 429 status codes. If not called 3 retries starting with 500ms delay are made.
+func (c *CallbackClient) SetRetries(retries int, backoff time.Duration) {
+	c.retries = retries
+	c.backoff = backoff
+}
+
+// State notifies Yandex about new states of user devices capabilities and properties
+func (c *CallbackClient) State(ctx context.Context, userID string, devices []Device) error {
+	return c.send(ctx, "state", callbackPayload{userID, devices})
+}
+
+// Discovery notifies Yandex that list of user devices has changed, so Yandex requests it again
+func (c *CallbackClient) Discovery(ctx context.Context, userID string) error {
+	return c.send(ctx, "discovery", callbackPayload{UserID: userID})
+}
+
+func (c *CallbackClient) send(ctx context.Context, kind string, p callbackPayload) error {
+	ts := c.now()
+	body, err := json.Marshal(callbackRequest{float64(ts.UnixNano()) / float64(time.Second), p})
+	if err != nil {
+		return fmt.Errorf("Error marshaling %v callback: %v", kind, err)
+	}
+	URL := fmt.Sprintf("%v/skills/%v/callback/%v", c.baseURL, c.skillID, kind)
+
+	delay := c.backoff
+	for attempt := 0; ; attempt++ {
+		retry, err := c.post(ctx, URL, body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= c.retries {
+			return fmt.Errorf("Error sending %v callback: %v", kind, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("Error sending %v callback: %v", kind, ctx.Err())
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// post sends callback and reports if failed request may be retried
+func (c *CallbackClient) post(ctx context.Context, URL string, body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Authorization", "OAuth "+c.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+
+	data, _ := ioutil.ReadAll(resp.Body)
+	var r callbackResponse
+	msg := string(data)
+	if json.Unmarshal(data, &r) == nil && r.ErrorMessage != "" {
+		msg = r.ErrorMessage
+	}
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("status %v: %v", resp.StatusCode, msg)
+}
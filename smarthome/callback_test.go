@@ -0,0 +1,114 @@
+package smarthome
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type callbackServer struct {
+	statuses []int
+	bodies   []string
+	paths    []string
+	auth     []string
+}
+
+func (s *callbackServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+	s.bodies = append(s.bodies, string(body))
+	s.paths = append(s.paths, r.URL.Path)
+	s.auth = append(s.auth, r.Header.Get("Authorization"))
+
+	status := http.StatusAccepted
+	if len(s.statuses) > 0 {
+		status, s.statuses = s.statuses[0], s.statuses[1:]
+	}
+	w.WriteHeader(status)
+	if status == http.StatusAccepted {
+		w.Write([]byte(`{"request_id": "1", "status": "ok"}`))
+	} else {
+		w.Write([]byte(`{"request_id": "1", "status": "error", "error_code": "ERROR", "error_message": "Something went wrong"}`))
+	}
+}
+
+func newTestCallbackClient(s *callbackServer) (*CallbackClient, func()) {
+	srv := httptest.NewServer(s)
+	c := NewCallbackClient("skill-1", "token")
+	c.SetBaseURL(srv.URL + "/api/v1")
+	c.SetHTTPClient(srv.Client())
+	c.SetRetries(2, time.Millisecond)
+	c.now = func() time.Time {
+		return time.Unix(1590000000, 500000000)
+	}
+	return c, srv.Close
+}
+
+func TestCallbackState(t *testing.T) {
+	s := &callbackServer{}
+	c, stop := newTestCallbackClient(s)
+	defer stop()
+
+	err := c.State(context.Background(), "user-1", []Device{{
+		ID:           "lamp-1",
+		Capabilities: []Capability{{Type: CapabilityOnOff, State: &CapabilityState{Instance: "on", Value: true}}},
+	}})
+	require.NoError(t, err)
+	require.Equal(t, []string{"/api/v1/skills/skill-1/callback/state"}, s.paths)
+	require.Equal(t, []string{"OAuth token"}, s.auth)
+	require.JSONEq(t, `{"ts": 1590000000.5, "payload": {"user_id": "user-1", "devices": [
+		{"id": "lamp-1", "capabilities": [{"type": "devices.capabilities.on_off", "state": {"instance": "on", "value": true}}]}
+	]}}`, s.bodies[0])
+}
+
+func TestCallbackDiscovery(t *testing.T) {
+	s := &callbackServer{}
+	c, stop := newTestCallbackClient(s)
+	defer stop()
+
+	require.NoError(t, c.Discovery(context.Background(), "user-1"))
+	require.Equal(t, []string{"/api/v1/skills/skill-1/callback/discovery"}, s.paths)
+	require.JSONEq(t, `{"ts": 1590000000.5, "payload": {"user_id": "user-1"}}`, s.bodies[0])
+}
+
+func TestCallbackRetries(t *testing.T) {
+	s := &callbackServer{statuses: []int{http.StatusInternalServerError, http.StatusTooManyRequests}}
+	c, stop := newTestCallbackClient(s)
+	defer stop()
+
+	require.NoError(t, c.Discovery(context.Background(), "user-1"))
+	require.Len(t, s.bodies, 3)
+
+	s = &callbackServer{statuses: []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway}}
+	c, stop = newTestCallbackClient(s)
+	defer stop()
+
+	err := c.Discovery(context.Background(), "user-1")
+	require.EqualError(t, err, "Error sending discovery callback: status 502: Something went wrong")
+	require.Len(t, s.bodies, 3)
+
+	s = &callbackServer{statuses: []int{http.StatusBadRequest}}
+	c, stop = newTestCallbackClient(s)
+	defer stop()
+
+	err = c.Discovery(context.Background(), "user-1")
+	require.EqualError(t, err, "Error sending discovery callback: status 400: Something went wrong")
+	require.Len(t, s.bodies, 1)
+}
+
+func TestCallbackContext(t *testing.T) {
+	s := &callbackServer{statuses: []int{http.StatusInternalServerError}}
+	c, stop := newTestCallbackClient(s)
+	defer stop()
+	c.SetRetries(5, time.Hour)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := c.Discovery(ctx, "user-1")
+	require.EqualError(t, err, "Error sending discovery callback: context deadline exceeded")
+	require.Len(t, s.bodies, 1)
+}
@@ -0,0 +1,202 @@
+package smarthome
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/temapavloff/galice"
+)
+
+// ErrUnauthorized must be returned by Provider if user token is invalid,
+// so Yandex asks user to link account again
+var ErrUnauthorized = errors.New("Unauthorized")
+
+// Provider is an implementation of smart home provider logic. Every method receives
+// OAuth token of the user issued by provider during account linking.
+type Provider interface {
+	// Devices returns ID of user in provider system and list of user devices
+	Devices(ctx context.Context, token string) (string, []Device, error)
+	// Query returns current states of requested devices
+	Query(ctx context.Context, token string, devices []Device) ([]Device, error)
+	// Action changes states of devices capabilities and returns results for every capability
+	Action(ctx context.Context, token string, devices []Device) ([]Device, error)
+	// Unlink is called when user unlinks provider account
+	Unlink(ctx context.Context, token string) error
+}
+
+// errBadRequest is returned for requests which cannot be decoded
+var errBadRequest = errors.New("Bad request")
+
+type requestIDKey struct{}
+
+// RequestID returns ID of Yandex request (X-Request-Id header) handled with ctx
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// BearerToken extracts OAuth token from Authorization header
+func BearerToken(r *http.Request) (string, bool) {
+	h := r.Header.Get("Authorization")
+	if len(h) < 7 || !strings.EqualFold(h[:7], "Bearer ") {
+		return "", false
+	}
+	token := strings.TrimSpace(h[7:])
+	return token, token != ""
+}
+
+// devicesRequest is a body of query and action requests
+type devicesRequest struct {
+	Devices []Device `json:"devices"`
+	Payload struct {
+		Devices []Device `json:"devices"`
+	} `json:"payload"`
+}
+
+// devicesPayload is a payload of devices, query and action responses
+type devicesPayload struct {
+	UserID  string   `json:"user_id,omitempty"`
+	Devices []Device `json:"devices"`
+}
+
+// response is a body of provider responses
+type response struct {
+	RequestID string          `json:"request_id"`
+	Payload   *devicesPayload `json:"payload,omitempty"`
+}
+
+type handler struct {
+	provider Provider
+	logger   galice.Logger
+}
+
+// NewHandler creates http.Handler for smart home provider endpoints: HEAD /v1.0,
+// POST /v1.0/user/unlink, GET /v1.0/user/devices, POST /v1.0/user/devices/query and
+// POST /v1.0/user/devices/action. Use http.StripPrefix if endpoints are not served
+// from the root. Errors returned by provider are passed to logger.
+func NewHandler(p Provider, logger galice.Logger) http.Handler {
+	return &handler{p, logger}
+}
+
+// ServeHTTP implements http.Handler interface
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	if path == "/v1.0" {
+		if r.Method != http.MethodHead && r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	var method string
+	var fn func(ctx context.Context, token string, r *http.Request) (*devicesPayload, error)
+	switch path {
+	case "/v1.0/user/unlink":
+		method, fn = http.MethodPost, h.unlink
+	case "/v1.0/user/devices":
+		method, fn = http.MethodGet, h.devices
+	case "/v1.0/user/devices/query":
+		method, fn = http.MethodPost, h.query
+	case "/v1.0/user/devices/action":
+		method, fn = http.MethodPost, h.action
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if r.Method != method {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	token, ok := BearerToken(r)
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	reqID := r.Header.Get("X-Request-Id")
+	ctx := context.WithValue(r.Context(), requestIDKey{}, reqID)
+	payload, err := fn(ctx, token, r)
+	switch {
+	case errors.Is(err, ErrUnauthorized):
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	case errors.Is(err, errBadRequest):
+		h.logger(fmt.Errorf("Smart home %v error (request_id: %v): %v", path, reqID, err))
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	case err != nil:
+		h.logger(fmt.Errorf("Smart home %v error (request_id: %v): %v", path, reqID, err))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err = json.NewEncoder(&buf).Encode(response{reqID, payload}); err != nil {
+		h.logger(fmt.Errorf("Error marshaling smart home response (request_id: %v): %v", reqID, err))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(buf.Bytes())
+}
+
+func (h *handler) unlink(ctx context.Context, token string, r *http.Request) (*devicesPayload, error) {
+	return nil, h.provider.Unlink(ctx, token)
+}
+
+func (h *handler) devices(ctx context.Context, token string, r *http.Request) (*devicesPayload, error) {
+	userID, devices, err := h.provider.Devices(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	return &devicesPayload{userID, nonNil(devices)}, nil
+}
+
+func (h *handler) query(ctx context.Context, token string, r *http.Request) (*devicesPayload, error) {
+	req, err := decodeDevicesRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	devices, err := h.provider.Query(ctx, token, req.Devices)
+	if err != nil {
+		return nil, err
+	}
+	return &devicesPayload{Devices: nonNil(devices)}, nil
+}
+
+func (h *handler) action(ctx context.Context, token string, r *http.Request) (*devicesPayload, error) {
+	req, err := decodeDevicesRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	devices, err := h.provider.Action(ctx, token, req.Payload.Devices)
+	if err != nil {
+		return nil, err
+	}
+	return &devicesPayload{Devices: nonNil(devices)}, nil
+}
+
+func decodeDevicesRequest(r *http.Request) (devicesRequest, error) {
+	var req devicesRequest
+	if r.Body == nil {
+		return req, fmt.Errorf("%w: empty request body", errBadRequest)
+	}
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return req, fmt.Errorf("%w: %v", errBadRequest, err)
+	}
+	return req, nil
+}
+
+func nonNil(devices []Device) []Device {
+	if devices == nil {
+		return []Device{}
+	}
+	return devices
+}
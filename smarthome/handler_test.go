@@ -0,0 +1,181 @@
+package smarthome
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testRequestID = "ff36a3cc-ec34-11e6-b1a0-64510650abcf"
+
+type testProvider struct {
+	unlinked []string
+}
+
+func (p *testProvider) Devices(ctx context.Context, token string) (string, []Device, error) {
+	if token != "valid" {
+		return "", nil, ErrUnauthorized
+	}
+	return "user-1", []Device{{
+		ID:          "lamp-1",
+		Name:        "Лампа",
+		Description: "Лампа в гостиной",
+		Room:        "Гостиная",
+		Type:        DeviceTypeLight,
+		CustomData:  map[string]int{"channel": 1},
+		Capabilities: []Capability{
+			{Type: CapabilityOnOff, Retrievable: true},
+			{Type: CapabilityRange, Retrievable: true, Parameters: map[string]interface{}{
+				"instance": "brightness",
+				"unit":     "unit.percent",
+				"range":    map[string]int{"min": 0, "max": 100, "precision": 1},
+			}},
+		},
+		Properties: []Property{
+			{Type: PropertyFloat, Retrievable: true, Parameters: map[string]string{
+				"instance": "temperature",
+				"unit":     "unit.temperature.celsius",
+			}},
+		},
+		DeviceInfo: &DeviceInfo{"Galice", "Lamp", "1.0", "1.2"},
+	}}, nil
+}
+
+func (p *testProvider) Query(ctx context.Context, token string, devices []Device) ([]Device, error) {
+	var res []Device
+	for _, d := range devices {
+		if d.ID != "lamp-1" {
+			res = append(res, Device{ID: d.ID, ErrorCode: ErrorCodeDeviceNotFound, ErrorMessage: "Unknown device"})
+			continue
+		}
+		res = append(res, Device{
+			ID: d.ID,
+			Capabilities: []Capability{
+				{Type: CapabilityOnOff, State: &CapabilityState{Instance: "on", Value: false}},
+				{Type: CapabilityRange, State: &CapabilityState{Instance: "brightness", Value: 75}},
+			},
+			Properties: []Property{
+				{Type: PropertyFloat, State: &PropertyState{Instance: "temperature", Value: 21.5}},
+			},
+		})
+	}
+	return res, nil
+}
+
+func (p *testProvider) Action(ctx context.Context, token string, devices []Device) ([]Device, error) {
+	if RequestID(ctx) != testRequestID {
+		return nil, errors.New("unexpected request ID")
+	}
+	var res []Device
+	for _, d := range devices {
+		r := Device{ID: d.ID}
+		for _, c := range d.Capabilities {
+			r.Capabilities = append(r.Capabilities, Capability{
+				Type:  c.Type,
+				State: &CapabilityState{Instance: c.State.Instance, ActionResult: &ActionResult{Status: StatusDone}},
+			})
+		}
+		res = append(res, r)
+	}
+	return res, nil
+}
+
+func (p *testProvider) Unlink(ctx context.Context, token string) error {
+	p.unlinked = append(p.unlinked, token)
+	return nil
+}
+
+func readFixture(t *testing.T, name string) string {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", name))
+	require.NoError(t, err)
+	return string(data)
+}
+
+func serve(t *testing.T, h http.Handler, method, path, token, body string) *httptest.ResponseRecorder {
+	var r *http.Request
+	var err error
+	if body == "" {
+		r, err = http.NewRequest(method, path, nil)
+	} else {
+		r, err = http.NewRequest(method, path, bytes.NewReader([]byte(body)))
+	}
+	require.NoError(t, err)
+	r.Header.Set("X-Request-Id", testRequestID)
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, r)
+	return rr
+}
+
+func TestHandlerEndpoints(t *testing.T) {
+	p := &testProvider{}
+	var errs []error
+	h := NewHandler(p, func(err error) {
+		errs = append(errs, err)
+	})
+
+	rr := serve(t, h, http.MethodHead, "/v1.0", "", "")
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	rr = serve(t, h, http.MethodGet, "/v1.0/user/devices", "valid", "")
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.JSONEq(t, readFixture(t, "devices_response.json"), rr.Body.String())
+	require.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+
+	rr = serve(t, h, http.MethodPost, "/v1.0/user/devices/query", "valid", readFixture(t, "query_request.json"))
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.JSONEq(t, readFixture(t, "query_response.json"), rr.Body.String())
+
+	rr = serve(t, h, http.MethodPost, "/v1.0/user/devices/action", "valid", readFixture(t, "action_request.json"))
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.JSONEq(t, readFixture(t, "action_response.json"), rr.Body.String())
+
+	rr = serve(t, h, http.MethodPost, "/v1.0/user/unlink", "valid", "")
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.JSONEq(t, `{"request_id": "`+testRequestID+`"}`, rr.Body.String())
+	require.Equal(t, []string{"valid"}, p.unlinked)
+
+	require.Empty(t, errs)
+}
+
+func TestHandlerErrors(t *testing.T) {
+	var errs []error
+	h := NewHandler(&testProvider{}, func(err error) {
+		errs = append(errs, err)
+	})
+
+	require.Equal(t, http.StatusUnauthorized, serve(t, h, http.MethodGet, "/v1.0/user/devices", "", "").Code)
+	require.Equal(t, http.StatusUnauthorized, serve(t, h, http.MethodGet, "/v1.0/user/devices", "invalid", "").Code)
+	require.Equal(t, http.StatusNotFound, serve(t, h, http.MethodGet, "/v1.0/user/unknown", "valid", "").Code)
+	require.Equal(t, http.StatusMethodNotAllowed, serve(t, h, http.MethodPost, "/v1.0/user/devices", "valid", "").Code)
+	require.Empty(t, errs)
+
+	require.Equal(t, http.StatusBadRequest, serve(t, h, http.MethodPost, "/v1.0/user/devices/query", "valid", "{").Code)
+	require.Len(t, errs, 1)
+}
+
+func TestBearerToken(t *testing.T) {
+	r, err := http.NewRequest(http.MethodGet, "/", nil)
+	require.NoError(t, err)
+
+	_, ok := BearerToken(r)
+	require.False(t, ok)
+
+	r.Header.Set("Authorization", "OAuth token")
+	_, ok = BearerToken(r)
+	require.False(t, ok)
+
+	r.Header.Set("Authorization", "bearer token")
+	token, ok := BearerToken(r)
+	require.True(t, ok)
+	require.Equal(t, "token", token)
+}
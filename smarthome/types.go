@@ -0,0 +1,111 @@
+// Package smarthome implements provider side of Yandex Smart Home REST protocol:
+// devices discovery, state query, actions and account unlinking.
+// It works alongside galice.Client, so one service may provide both
+// conversational skill and smart home skill.
+package smarthome
+
+// Device types
+const (
+	DeviceTypeLight      = "devices.types.light"
+	DeviceTypeSocket     = "devices.types.socket"
+	DeviceTypeSwitch     = "devices.types.switch"
+	DeviceTypeThermostat = "devices.types.thermostat"
+	DeviceTypeSensor     = "devices.types.sensor"
+	DeviceTypeOther      = "devices.types.other"
+)
+
+// Capability types
+const (
+	CapabilityOnOff        = "devices.capabilities.on_off"
+	CapabilityColorSetting = "devices.capabilities.color_setting"
+	CapabilityMode         = "devices.capabilities.mode"
+	CapabilityRange        = "devices.capabilities.range"
+	CapabilityToggle       = "devices.capabilities.toggle"
+)
+
+// Property types
+const (
+	PropertyFloat = "devices.properties.float"
+	PropertyEvent = "devices.properties.event"
+)
+
+// Action statuses
+const (
+	StatusDone  = "DONE"
+	StatusError = "ERROR"
+)
+
+// Error codes
+const (
+	ErrorCodeDeviceUnreachable = "DEVICE_UNREACHABLE"
+	ErrorCodeDeviceBusy        = "DEVICE_BUSY"
+	ErrorCodeDeviceNotFound    = "DEVICE_NOT_FOUND"
+	ErrorCodeInternalError     = "INTERNAL_ERROR"
+	ErrorCodeInvalidAction     = "INVALID_ACTION"
+	ErrorCodeInvalidValue      = "INVALID_VALUE"
+	ErrorCodeNotSupported      = "NOT_SUPPORTED_IN_CURRENT_MODE"
+)
+
+// ActionResult is a result of changing device or capability state
+type ActionResult struct {
+	Status       string `json:"status"` // StatusDone or StatusError
+	ErrorCode    string `json:"error_code,omitempty"`
+	ErrorMessage string `json:"error_message,omitempty"`
+}
+
+// CapabilityState is a state of device capability
+type CapabilityState struct {
+	Instance     string        `json:"instance"` // e.g. "on" for on_off or "brightness" for range
+	Value        interface{}   `json:"value,omitempty"`
+	ActionResult *ActionResult `json:"action_result,omitempty"`
+}
+
+// Capability is a device feature which can be changed by user, e.g. turning light on or off
+type Capability struct {
+	Type        string           `json:"type"`
+	Retrievable bool             `json:"retrievable,omitempty"` // can state be queried
+	Reportable  bool             `json:"reportable,omitempty"`  // is state reported with callbacks
+	Parameters  interface{}      `json:"parameters,omitempty"`
+	State       *CapabilityState `json:"state,omitempty"`
+}
+
+// PropertyState is a state of device property
+type PropertyState struct {
+	Instance string      `json:"instance"` // e.g. "temperature" for float property
+	Value    interface{} `json:"value"`
+}
+
+// Property is a device value which can be only read, e.g. temperature
+type Property struct {
+	Type        string         `json:"type"`
+	Retrievable bool           `json:"retrievable,omitempty"`
+	Reportable  bool           `json:"reportable,omitempty"`
+	Parameters  interface{}    `json:"parameters,omitempty"`
+	State       *PropertyState `json:"state,omitempty"`
+}
+
+// DeviceInfo describes device hardware
+type DeviceInfo struct {
+	Manufacturer string `json:"manufacturer,omitempty"`
+	Model        string `json:"model,omitempty"`
+	HWVersion    string `json:"hw_version,omitempty"`
+	SWVersion    string `json:"sw_version,omitempty"`
+}
+
+// Device is a smart home device. The same struct is used for discovery (all
+// description fields), query and action requests (ID, CustomData and states)
+// and their responses (ID, states and errors).
+type Device struct {
+	ID           string        `json:"id"`
+	Name         string        `json:"name,omitempty"`
+	Description  string        `json:"description,omitempty"`
+	Room         string        `json:"room,omitempty"`
+	Type         string        `json:"type,omitempty"`
+	CustomData   interface{}   `json:"custom_data,omitempty"`
+	Capabilities []Capability  `json:"capabilities,omitempty"`
+	Properties   []Property    `json:"properties,omitempty"`
+	DeviceInfo   *DeviceInfo   `json:"device_info,omitempty"`
+	ErrorCode    string        `json:"error_code,omitempty"`
+	ErrorMessage string        `json:"error_message,omitempty"`
+	ActionResult *ActionResult `json:"action_result,omitempty"`
+}
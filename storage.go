@@ -0,0 +1,91 @@
+package galice
+
+import (
+	"sync"
+	"time"
+)
+
+// Storage is a signature of session data storage used by Client to keep data between requests
+type Storage interface {
+	// Get returns value of key for session or nil if there is no such value
+	Get(sessionID, key string) ([]byte, error)
+	// Set sets value of key for session, nil value removes the key
+	Set(sessionID, key string, value []byte) error
+}
+
+// DefaultSessionTTL is a time after which unused sessions are removed from default MemoryStorage
+const DefaultSessionTTL = time.Hour
+
+// MemoryStorage is an in-memory Storage implementation. Data of sessions not used
+// longer than TTL is removed. Use it only for single instance skills, otherwise
+// provide your own Storage implementation backed by some database.
+type MemoryStorage struct {
+	mu          sync.Mutex
+	ttl         time.Duration
+	lastCleanup time.Time
+	sessions    map[string]*memorySession
+}
+
+type memorySession struct {
+	usedAt time.Time
+	values map[string][]byte
+}
+
+// NewMemoryStorage creates new MemoryStorage which keeps session data for ttl after last usage
+func NewMemoryStorage(ttl time.Duration) *MemoryStorage {
+	return &MemoryStorage{
+		ttl:         ttl,
+		lastCleanup: time.Now(),
+		sessions:    map[string]*memorySession{},
+	}
+}
+
+// Get implements Storage interface
+func (s *MemoryStorage) Get(sessionID, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess, ok := s.sessions[sessionID]
+	if !ok || time.Since(sess.usedAt) > s.ttl {
+		return nil, nil
+	}
+	sess.usedAt = time.Now()
+	return sess.values[key], nil
+}
+
+// Set implements Storage interface
+func (s *MemoryStorage) Set(sessionID, key string, value []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cleanup()
+
+	sess, ok := s.sessions[sessionID]
+	if !ok {
+		sess = &memorySession{values: map[string][]byte{}}
+		s.sessions[sessionID] = sess
+	} else if time.Since(sess.usedAt) > s.ttl {
+		// session is expired but not cleaned up yet, its values must not come back
+		sess.values = map[string][]byte{}
+	}
+	sess.usedAt = time.Now()
+	if value == nil {
+		delete(sess.values, key)
+	} else {
+		sess.values[key] = value
+	}
+	return nil
+}
+
+// cleanup removes expired sessions, it runs not more often than once per TTL
+func (s *MemoryStorage) cleanup() {
+	if time.Since(s.lastCleanup) < s.ttl {
+		return
+	}
+	s.lastCleanup = time.Now()
+	for id, sess := range s.sessions {
+		if time.Since(sess.usedAt) > s.ttl {
+			delete(s.sessions, id)
+		}
+	}
+}
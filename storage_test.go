@@ -0,0 +1,59 @@
+package galice
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStorage(t *testing.T) {
+	s := NewMemoryStorage(time.Hour)
+
+	v, err := s.Get("session", "key")
+	require.NoError(t, err)
+	require.Nil(t, v)
+
+	require.NoError(t, s.Set("session", "key", []byte("value")))
+	v, err = s.Get("session", "key")
+	require.NoError(t, err)
+	require.Equal(t, []byte("value"), v)
+
+	v, err = s.Get("other", "key")
+	require.NoError(t, err)
+	require.Nil(t, v)
+
+	require.NoError(t, s.Set("session", "key", nil))
+	v, err = s.Get("session", "key")
+	require.NoError(t, err)
+	require.Nil(t, v)
+}
+
+func TestMemoryStorageTTL(t *testing.T) {
+	s := NewMemoryStorage(time.Millisecond)
+	require.NoError(t, s.Set("session", "key", []byte("value")))
+	time.Sleep(5 * time.Millisecond)
+
+	v, err := s.Get("session", "key")
+	require.NoError(t, err)
+	require.Nil(t, v)
+
+	require.NoError(t, s.Set("other", "key", []byte("value")))
+	require.Len(t, s.sessions, 1)
+}
+
+func TestMemoryStorageExpiredSet(t *testing.T) {
+	s := NewMemoryStorage(50 * time.Millisecond)
+	require.NoError(t, s.Set("session", "old", []byte("stale")))
+	time.Sleep(100 * time.Millisecond)
+	// expired session is not cleaned up yet
+	s.lastCleanup = time.Now()
+
+	require.NoError(t, s.Set("session", "new", []byte("value")))
+	v, err := s.Get("session", "old")
+	require.NoError(t, err)
+	require.Nil(t, v)
+	v, err = s.Get("session", "new")
+	require.NoError(t, err)
+	require.Equal(t, []byte("value"), v)
+}
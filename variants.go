@@ -0,0 +1,121 @@
+package galice
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Variant is one of interchangeable texts of response
+type Variant struct {
+	Text string
+	TTS  string // if empty Text is used
+}
+
+// Response creates new response with text and TTS of current variant
+func (v Variant) Response(endSession bool) Response {
+	return NewResponse(v.Text, v.TTS, endSession)
+}
+
+// Variants is a set of interchangeable response texts which helps skill not to repeat
+// the same phrase over and over again
+type Variants struct {
+	mu    sync.Mutex
+	items []Variant
+	rnd   *rand.Rand
+}
+
+// variantsState is an order of variants saved to storage for round-robin selection
+type variantsState struct {
+	Order []int `json:"order"`
+	Pos   int   `json:"pos"`
+}
+
+// NewVariants creates new set of variants
+func NewVariants(items ...Variant) *Variants {
+	return &Variants{
+		items: items,
+		rnd:   rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// NewTextVariants creates new set of variants with TTS equal to text
+func NewTextVariants(texts ...string) *Variants {
+	items := make([]Variant, len(texts))
+	for n, t := range texts {
+		items[n] = Variant{Text: t}
+	}
+	return NewVariants(items...)
+}
+
+// SetSeed sets seed of random generator, use it in tests to get predictable variants
+func (v *Variants) SetSeed(seed int64) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.rnd = rand.New(rand.NewSource(seed))
+}
+
+// Random returns random variant
+func (v *Variants) Random() Variant {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if len(v.items) == 0 {
+		return Variant{}
+	}
+	return v.items[v.rnd.Intn(len(v.items))]
+}
+
+// Next returns variant which was not used in the session yet. Variants are picked
+// in random order, after all of them are used the order is shuffled again,
+// so the same variant is never returned twice in a row. Used variants are tracked
+// in storage under key, so different sets of variants must have different keys.
+func (v *Variants) Next(s Storage, sessionID, key string) (Variant, error) {
+	if len(v.items) == 0 {
+		return Variant{}, nil
+	}
+
+	var state variantsState
+	data, err := s.Get(sessionID, key)
+	if err != nil {
+		return Variant{}, fmt.Errorf("Unable to load variants state: %v", err)
+	}
+	if data != nil {
+		if err = json.Unmarshal(data, &state); err != nil {
+			return Variant{}, fmt.Errorf("Unable to load variants state: %v", err)
+		}
+	}
+
+	if len(state.Order) != len(v.items) || state.Pos >= len(state.Order) {
+		last := -1
+		if len(state.Order) == len(v.items) {
+			last = state.Order[len(state.Order)-1]
+		}
+		state = variantsState{v.shuffle(last), 0}
+	}
+
+	item := v.items[state.Order[state.Pos]]
+	state.Pos++
+
+	if data, err = json.Marshal(state); err != nil {
+		return Variant{}, fmt.Errorf("Unable to save variants state: %v", err)
+	}
+	if err = s.Set(sessionID, key, data); err != nil {
+		return Variant{}, fmt.Errorf("Unable to save variants state: %v", err)
+	}
+	return item, nil
+}
+
+// shuffle returns random order of variants which does not start with variant last
+func (v *Variants) shuffle(last int) []int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	order := v.rnd.Perm(len(v.items))
+	if len(order) > 1 && order[0] == last {
+		order[0], order[len(order)-1] = order[len(order)-1], order[0]
+	}
+	return order
+}
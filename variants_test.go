@@ -0,0 +1,53 @@
+package galice
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVariantsRandom(t *testing.T) {
+	v1 := NewTextVariants("Привет!", "Здравствуйте!", "Добрый день!")
+	v1.SetSeed(42)
+	v2 := NewTextVariants("Привет!", "Здравствуйте!", "Добрый день!")
+	v2.SetSeed(42)
+
+	seen := map[string]bool{}
+	for n := 0; n < 20; n++ {
+		r := v1.Random()
+		require.Equal(t, r, v2.Random())
+		seen[r.Text] = true
+	}
+	require.Len(t, seen, 3)
+
+	require.Equal(t, Variant{}, NewVariants().Random())
+	require.Equal(t, NewResponse("Пока", "пока-пока", true), Variant{"Пока", "пока-пока"}.Response(true))
+}
+
+func TestVariantsNext(t *testing.T) {
+	s := NewMemoryStorage(time.Hour)
+	v := NewTextVariants("1", "2", "3")
+	v.SetSeed(1)
+
+	var prev string
+	for round := 0; round < 10; round++ {
+		seen := map[string]bool{}
+		for n := 0; n < 3; n++ {
+			r, err := v.Next(s, "session", "greeting")
+			require.NoError(t, err)
+			require.NotEqual(t, prev, r.Text)
+			seen[r.Text] = true
+			prev = r.Text
+		}
+		require.Len(t, seen, 3)
+	}
+
+	r, err := v.Next(s, "other", "greeting")
+	require.NoError(t, err)
+	require.NotEmpty(t, r.Text)
+
+	require.NoError(t, s.Set("session", "broken", []byte("{")))
+	_, err = v.Next(s, "session", "broken")
+	require.Error(t, err)
+}